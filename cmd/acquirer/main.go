@@ -2,17 +2,28 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/josuesantos1/emv/internal/clock"
 )
 
 type AuthorizationRequest struct {
-	Pan          string    `json:"pan"`
-	DataValidade time.Time `json:"data_validade"`
-	CVM          string    `json:"cvm"`
+	Pan              string    `json:"pan"`
+	DataValidade     time.Time `json:"data_validade"`
+	CVM              string    `json:"cvm"`
+	Amount           uint64    `json:"amount"`
+	AmountOther      uint64    `json:"amount_other,omitempty"`
+	Currency         string    `json:"currency"`
+	CurrencyExponent int       `json:"currency_exponent"`
+	Country          string    `json:"country,omitempty"`
+	TVR              string    `json:"tvr"`
+	TSI              string    `json:"tsi"`
 }
 
 type AuthorizationResponse struct {
@@ -21,15 +32,36 @@ type AuthorizationResponse struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+type acquirer struct {
+	clock clock.Clock
+	rng   *rand.Rand
+	mu    sync.Mutex
+}
+
 func main() {
-	http.HandleFunc("/authorize", authorizeHandler)
+	tz := flag.String("tz", "", "time zone of response timestamps (default: local)")
+	flag.Parse()
+
+	loc := time.Local
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			log.Fatalf("invalid time zone %q: %v", *tz, err)
+		}
+	}
+
+	a := &acquirer{
+		clock: clock.System{Location: loc},
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	http.HandleFunc("/authorize", a.authorizeHandler)
 
 	port := ":8080"
 	fmt.Printf("Mock server Acquirer running on port %s\n", port)
 	log.Fatal(http.ListenAndServe(port, nil))
 }
 
-func authorizeHandler(w http.ResponseWriter, r *http.Request) {
+func (a *acquirer) authorizeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -37,16 +69,17 @@ func authorizeHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req AuthorizationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, "Invalid request body", http.StatusBadRequest)
+		a.respondError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	approved := rng.Intn(100) < 70
+	a.mu.Lock()
+	approved := a.rng.Intn(100) < 70
+	a.mu.Unlock()
 
 	response := AuthorizationResponse{
 		Approved:  approved,
-		Timestamp: time.Now(),
+		Timestamp: a.clock.Now(),
 	}
 
 	if approved {
@@ -55,20 +88,20 @@ func authorizeHandler(w http.ResponseWriter, r *http.Request) {
 		response.Message = "Transaction declined by acquirer"
 	}
 
-	log.Printf("Authorization request: PAN=%s, Approved=%v\n", maskPan(req.Pan), approved)
+	log.Printf("Authorization request: PAN=%s, Amount=%d %s (exp %d), TVR=%s, TSI=%s, Approved=%v\n", maskPan(req.Pan), req.Amount, req.Currency, req.CurrencyExponent, req.TVR, req.TSI, approved)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
-func respondError(w http.ResponseWriter, message string, statusCode int) {
+func (a *acquirer) respondError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(AuthorizationResponse{
 		Approved:  false,
 		Message:   message,
-		Timestamp: time.Now(),
+		Timestamp: a.clock.Now(),
 	})
 }
 
@@ -3,27 +3,56 @@ package main
 import (
 	"bufio"
 	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/josuesantos1/emv/internal/clock"
 	domain "github.com/josuesantos1/emv/internal/domain"
 	"github.com/josuesantos1/emv/internal/gateway"
 	"github.com/josuesantos1/emv/internal/handlers"
+	"github.com/josuesantos1/emv/internal/hotlist"
 	"github.com/josuesantos1/emv/internal/logger"
+	"github.com/josuesantos1/emv/internal/risk"
 	"github.com/josuesantos1/emv/pkg/tlv"
 )
 
 func main() {
-	transactionLogger := logger.NewJSONLogger("transactions.json")
+	tz := flag.String("tz", "", "terminal time zone, e.g. America/Sao_Paulo (default: local)")
+	at := flag.String("now", "", "process every transaction at this RFC 3339 instant, for replays")
+	hotlistPath := flag.String("hotlist", "", "CSV file of blocked cards (pan,sequence,reason,expiry)")
+	floorLimit := flag.Uint64("floor-limit", 0, "terminal floor limit in minor units; transactions at or above it go online")
+	flag.Parse()
+
+	clk, err := newClock(*tz, *at)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	transactionLogger := logger.NewJSONLogger("transactions.json", logger.WithClock(clk))
 	gw := gateway.NewHTTPGateway("http://localhost:8080")
+	var riskOpts []risk.Option
+	if *hotlistPath != "" {
+		blocked, err := hotlist.LoadFile(*hotlistPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		riskOpts = append(riskOpts, risk.WithExceptionFile(blocked))
+	}
+	processor := handlers.NewProcessor(gw,
+		handlers.WithClock(clk),
+		handlers.WithRiskManager(risk.NewManager(risk.Config{FloorLimit: *floorLimit}, riskOpts...)),
+	)
 	scanner := bufio.NewScanner(os.Stdin)
 
 	fmt.Println("EMV Transaction Processor")
 	fmt.Println("=========================")
-	fmt.Println("Enter TLV hex data (or 'exit' to quit)")
-	fmt.Println("Exemple: 5A0845395787636214865F2404251200009F340400000000")
+	fmt.Println("Enter TLV hex data, 'dump <hex>' to inspect it (or 'exit' to quit)")
+	fmt.Println("Exemple: 5A0845395787636214865F24033012319F34031F0002")
 	fmt.Println()
 
 	for {
@@ -44,13 +73,60 @@ func main() {
 			break
 		}
 
-		if err := processTransaction(raw, transactionLogger, gw); err != nil {
-			fmt.Printf("Error: %v\n\n", err)
+		if cmd, arg, found := strings.Cut(raw, " "); found && cmd == "dump" {
+			arg = strings.TrimSpace(arg)
+			if err := dumpTLV(arg); err != nil {
+				printError(arg, err)
+			}
+			continue
+		}
+
+		if err := processTransaction(raw, transactionLogger, processor); err != nil {
+			printError(raw, err)
+		}
+	}
+}
+
+// newClock builds the terminal clock from the -tz and -now flags.
+func newClock(tz, at string) (clock.Clock, error) {
+	loc := time.Local
+	if tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", tz, err)
 		}
 	}
+
+	if at == "" {
+		return clock.System{Location: loc}, nil
+	}
+
+	instant, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instant %q: %w", at, err)
+	}
+	return clock.Fixed(instant.In(loc)), nil
+}
+
+// printError reports err to the operator. For TLV decoding failures it also
+// points at the offending byte of the hex input.
+func printError(raw string, err error) {
+	fmt.Printf("Error: %v\n", err)
+
+	var parseErr *tlv.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Printf("Offset: %d\n", parseErr.Offset)
+		if parseErr.Tag != 0 {
+			fmt.Printf("Tag: %s\n", parseErr.Tag)
+		}
+		fmt.Printf("  %s\n", raw)
+		fmt.Printf("  %s^\n", strings.Repeat(" ", parseErr.Offset*2))
+	}
+
+	fmt.Println()
 }
 
-func processTransaction(raw string, transactionLogger *logger.JSONLogger, gw *gateway.HTTPGateway) error {
+func dumpTLV(raw string) error {
 	data, err := hex.DecodeString(raw)
 	if err != nil {
 		return fmt.Errorf("invalid hex data: %v", err)
@@ -59,7 +135,26 @@ func processTransaction(raw string, transactionLogger *logger.JSONLogger, gw *ga
 	parser := tlv.Parser{}
 	tlvs, err := parser.Parse(data)
 	if err != nil {
-		return fmt.Errorf("failed to parse TLV: %v", err)
+		return fmt.Errorf("failed to parse TLV: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Print(tlv.DumpString(tlvs))
+	fmt.Println()
+
+	return nil
+}
+
+func processTransaction(raw string, transactionLogger *logger.JSONLogger, processor *handlers.Processor) error {
+	data, err := hex.DecodeString(raw)
+	if err != nil {
+		return fmt.Errorf("invalid hex data: %v", err)
+	}
+
+	parser := tlv.Parser{}
+	tlvs, err := parser.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse TLV: %w", err)
 	}
 
 	transaction := &domain.Tlv{}
@@ -67,7 +162,7 @@ func processTransaction(raw string, transactionLogger *logger.JSONLogger, gw *ga
 		return fmt.Errorf("failed to populate transaction: %v", err)
 	}
 
-	result, err := handlers.ProcessTransaction(transaction, gw)
+	result, err := processor.Process(transaction)
 	if err != nil {
 		return fmt.Errorf("transaction processing failed: %v", err)
 	}
@@ -84,8 +179,35 @@ func processTransaction(raw string, transactionLogger *logger.JSONLogger, gw *ga
 	}
 	fmt.Printf("Message: %s\n", result.Message)
 	fmt.Printf("PAN: %s\n", result.Pan)
+	fmt.Printf("Brand: %s\n", result.Brand)
 	fmt.Printf("Expiry Date: %s\n", result.DataValidade.Format("01/2006"))
-	fmt.Printf("CVM: %s\n", result.CVM)
+	if result.Amount.Currency != 0 {
+		fmt.Printf("Amount: %s\n", result.Amount)
+		if result.AmountOther.Amount > 0 {
+			fmt.Printf("Amount Other: %s\n", result.AmountOther)
+		}
+	}
+	fmt.Printf("CVM: %s (%s)\n", result.CVM.Hex(), result.CVM)
+	if result.ServiceCode != "" {
+		fmt.Printf("Service Code: %s\n", result.ServiceCode)
+		if result.OnlineRequired {
+			fmt.Println("Online authorization required by service code")
+		}
+		if result.PINRequired {
+			fmt.Println("PIN required by service code")
+		}
+	}
+	fmt.Printf("TVR: %s\n", result.TVR)
+	for _, name := range result.TVR.Names() {
+		fmt.Printf("  - %s\n", name)
+	}
+	fmt.Printf("TSI: %s\n", result.TSI)
+	if result.Cryptogram != domain.CryptogramNone {
+		fmt.Printf("Cryptogram: %s\n", result.Cryptogram)
+	}
+	for _, conflict := range result.Conflicts {
+		fmt.Printf("Warning: %s\n", conflict)
+	}
 	fmt.Printf("Timestamp: %s\n", result.Timestamp.Format("2006-01-02 15:04:05"))
 	fmt.Println("========================================")
 
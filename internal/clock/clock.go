@@ -0,0 +1,31 @@
+// Package clock abstracts the current time so that validation, logging and
+// replays can run at a fixed instant or in a configured time zone.
+package clock
+
+import "time"
+
+// Clock reports the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System reads the system clock. When Location is set the time is
+// reported in that zone, otherwise in the local zone.
+type System struct {
+	Location *time.Location
+}
+
+func (s System) Now() time.Time {
+	now := time.Now()
+	if s.Location != nil {
+		return now.In(s.Location)
+	}
+	return now
+}
+
+// Fixed always reports the same instant.
+type Fixed time.Time
+
+func (f Fixed) Now() time.Time {
+	return time.Time(f)
+}
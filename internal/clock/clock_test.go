@@ -0,0 +1,46 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFixed_Now(t *testing.T) {
+	want := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	c := Fixed(want)
+
+	if got := c.Now(); !got.Equal(want) {
+		t.Errorf("Fixed.Now() = %v, want %v", got, want)
+	}
+	if got := c.Now(); !got.Equal(want) {
+		t.Errorf("Fixed.Now() second call = %v, want %v", got, want)
+	}
+}
+
+func TestSystem_Now(t *testing.T) {
+	loc := time.FixedZone("BRT", -3*60*60)
+
+	tests := []struct {
+		name     string
+		clock    System
+		wantZone *time.Location
+	}{
+		{name: "local zone", clock: System{}, wantZone: time.Local},
+		{name: "configured zone", clock: System{Location: loc}, wantZone: loc},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			got := tt.clock.Now()
+			after := time.Now()
+
+			if got.Before(before) || got.After(after) {
+				t.Errorf("System.Now() = %v, want between %v and %v", got, before, after)
+			}
+			if got.Location() != tt.wantZone {
+				t.Errorf("System.Now() location = %v, want %v", got.Location(), tt.wantZone)
+			}
+		})
+	}
+}
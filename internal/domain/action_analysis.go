@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// CryptogramType is the Application Cryptogram the terminal requests in
+// the first GENERATE AC, as decided by terminal action analysis.
+type CryptogramType int
+
+const (
+	// CryptogramNone means no cryptogram was requested yet: the
+	// transaction has not been through terminal action analysis.
+	CryptogramNone CryptogramType = iota
+	// CryptogramAAC declines the transaction offline.
+	CryptogramAAC
+	// CryptogramTC approves the transaction offline.
+	CryptogramTC
+	// CryptogramARQC sends the transaction online for authorization.
+	CryptogramARQC
+)
+
+func (c CryptogramType) String() string {
+	switch c {
+	case CryptogramNone:
+		return "None"
+	case CryptogramAAC:
+		return "AAC"
+	case CryptogramTC:
+		return "TC"
+	case CryptogramARQC:
+		return "ARQC"
+	}
+	return fmt.Sprintf("CryptogramType(%d)", int(c))
+}
+
+const actionCodeLen = 5
+
+// ActionCode is a Terminal or Issuer Action Code: a bitmap laid out like
+// the TVR selecting the TVR bits that trigger the action.
+type ActionCode [actionCodeLen]byte
+
+// ActionCodeNone and ActionCodeAll select no TVR bit and every TVR bit.
+var (
+	ActionCodeNone = ActionCode{}
+	ActionCodeAll  = ActionCode{0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+)
+
+func ParseActionCode(value []byte) (ActionCode, error) {
+	if len(value) != actionCodeLen {
+		return ActionCode{}, fmt.Errorf("invalid action code: expected %d bytes, got %d", actionCodeLen, len(value))
+	}
+	var code ActionCode
+	copy(code[:], value)
+	return code, nil
+}
+
+// UnmarshalBinary decodes the raw value of an Issuer Action Code.
+func (a *ActionCode) UnmarshalBinary(value []byte) error {
+	parsed, err := ParseActionCode(value)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Matches reports whether any bit set in tvr is selected by a.
+func (a ActionCode) Matches(tvr TVR) bool {
+	for i := range a {
+		if a[i]&tvr[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (a ActionCode) Hex() string {
+	return fmt.Sprintf("%X", a[:])
+}
+
+// ActionCodes groups the Denial, Online and Default action codes of the
+// terminal (TAC) or of the issuer (IAC).
+type ActionCodes struct {
+	Denial  ActionCode
+	Online  ActionCode
+	Default ActionCode
+}
+
+// AnalyzeActions performs terminal action analysis as described in EMV
+// 4.3 Book 3 §10.7. The transaction is declined when the TVR matches a
+// Denial code; otherwise an online capable terminal goes online when it
+// matches an Online code and an offline only terminal applies the Default
+// codes. Anything else is approved offline.
+func AnalyzeActions(tvr TVR, terminal, issuer ActionCodes, onlineCapable bool) CryptogramType {
+	if terminal.Denial.Matches(tvr) || issuer.Denial.Matches(tvr) {
+		return CryptogramAAC
+	}
+
+	if !onlineCapable {
+		return DefaultAction(tvr, terminal, issuer)
+	}
+
+	if terminal.Online.Matches(tvr) || issuer.Online.Matches(tvr) {
+		return CryptogramARQC
+	}
+
+	return CryptogramTC
+}
+
+// DefaultAction decides a transaction that should have gone online but
+// could not: it is declined when the TVR matches a Default code and
+// approved offline otherwise.
+func DefaultAction(tvr TVR, terminal, issuer ActionCodes) CryptogramType {
+	if terminal.Default.Matches(tvr) || issuer.Default.Matches(tvr) {
+		return CryptogramAAC
+	}
+	return CryptogramTC
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/josuesantos1/emv/pkg/tlv"
+)
+
+func tvrWith(bits ...tlv.Bit) TVR {
+	var tvr TVR
+	for _, bit := range bits {
+		tvr.Set(bit)
+	}
+	return tvr
+}
+
+func TestAnalyzeActions(t *testing.T) {
+	terminal := ActionCodes{
+		Denial:  ActionCode{0x00, 0x10, 0x00, 0x00, 0x00},
+		Online:  ActionCode{0xDC, 0x40, 0x04, 0xF8, 0x00},
+		Default: ActionCode{0xDC, 0x40, 0x00, 0xA8, 0x00},
+	}
+	noIssuer := ActionCodes{}
+
+	tests := []struct {
+		name          string
+		tvr           TVR
+		terminal      ActionCodes
+		issuer        ActionCodes
+		onlineCapable bool
+		want          CryptogramType
+	}{
+		{
+			name:          "clean TVR approved offline",
+			terminal:      terminal,
+			issuer:        noIssuer,
+			onlineCapable: true,
+			want:          CryptogramTC,
+		},
+		{
+			name:          "terminal denial code declines",
+			tvr:           tvrWith(tlv.TVRServiceNotAllowed),
+			terminal:      terminal,
+			issuer:        noIssuer,
+			onlineCapable: true,
+			want:          CryptogramAAC,
+		},
+		{
+			name:          "issuer denial code declines",
+			tvr:           tvrWith(tlv.TVRNewCard),
+			terminal:      terminal,
+			issuer:        ActionCodes{Denial: ActionCode{0x00, 0x08, 0x00, 0x00, 0x00}},
+			onlineCapable: true,
+			want:          CryptogramAAC,
+		},
+		{
+			name:          "terminal online code goes online",
+			tvr:           tvrWith(tlv.TVROfflineDataAuthenticationNotPerformed),
+			terminal:      terminal,
+			issuer:        noIssuer,
+			onlineCapable: true,
+			want:          CryptogramARQC,
+		},
+		{
+			name:          "issuer online code goes online",
+			tvr:           tvrWith(tlv.TVRPINNotEntered),
+			terminal:      terminal,
+			issuer:        ActionCodes{Online: ActionCode{0x00, 0x00, 0x08, 0x00, 0x00}},
+			onlineCapable: true,
+			want:          CryptogramARQC,
+		},
+		{
+			name:     "offline only terminal declines on default code",
+			tvr:      tvrWith(tlv.TVROfflineDataAuthenticationNotPerformed),
+			terminal: terminal,
+			issuer:   noIssuer,
+			want:     CryptogramAAC,
+		},
+		{
+			name:     "offline only terminal approves without default match",
+			tvr:      tvrWith(tlv.TVRPINNotEntered),
+			terminal: terminal,
+			issuer:   noIssuer,
+			want:     CryptogramTC,
+		},
+		{
+			name:          "denial takes precedence over online",
+			tvr:           tvrWith(tlv.TVRServiceNotAllowed, tlv.TVROfflineDataAuthenticationNotPerformed),
+			terminal:      terminal,
+			issuer:        noIssuer,
+			onlineCapable: true,
+			want:          CryptogramAAC,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnalyzeActions(tt.tvr, tt.terminal, tt.issuer, tt.onlineCapable); got != tt.want {
+				t.Errorf("AnalyzeActions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		tvr      TVR
+		terminal ActionCodes
+		issuer   ActionCodes
+		want     CryptogramType
+	}{
+		{
+			name: "no default match approves offline",
+			tvr:  tvrWith(tlv.TVROfflineDataAuthenticationNotPerformed),
+			want: CryptogramTC,
+		},
+		{
+			name:     "terminal default code declines",
+			tvr:      tvrWith(tlv.TVROfflineDataAuthenticationNotPerformed),
+			terminal: ActionCodes{Default: ActionCode{0x80, 0x00, 0x00, 0x00, 0x00}},
+			want:     CryptogramAAC,
+		},
+		{
+			name:   "issuer default code declines",
+			tvr:    tvrWith(tlv.TVRFloorLimitExceeded),
+			issuer: ActionCodes{Default: ActionCodeAll},
+			want:   CryptogramAAC,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultAction(tt.tvr, tt.terminal, tt.issuer); got != tt.want {
+				t.Errorf("DefaultAction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseActionCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "five bytes", value: "DC4004F800", want: "DC4004F800"},
+		{name: "too short", value: "DC4004F8", wantErr: true},
+		{name: "too long", value: "DC4004F80000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseActionCode(hexToBytes(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseActionCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Hex() != tt.want {
+				t.Errorf("ParseActionCode() = %v, want %v", got.Hex(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTlv_IssuerActionCodes(t *testing.T) {
+	online := ActionCode{0x00, 0x00, 0x08, 0x00, 0x00}
+
+	tests := []struct {
+		name string
+		tlv  Tlv
+		want ActionCodes
+	}{
+		{
+			name: "absent codes use EMV defaults",
+			want: ActionCodes{Denial: ActionCodeNone, Online: ActionCodeAll, Default: ActionCodeAll},
+		},
+		{
+			name: "present codes are used as is",
+			tlv:  Tlv{IACOnline: &online},
+			want: ActionCodes{Denial: ActionCodeNone, Online: online, Default: ActionCodeAll},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tlv.IssuerActionCodes(); got != tt.want {
+				t.Errorf("Tlv.IssuerActionCodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCryptogramType_String(t *testing.T) {
+	tests := []struct {
+		cryptogram CryptogramType
+		want       string
+	}{
+		{cryptogram: CryptogramNone, want: "None"},
+		{cryptogram: CryptogramAAC, want: "AAC"},
+		{cryptogram: CryptogramTC, want: "TC"},
+		{cryptogram: CryptogramARQC, want: "ARQC"},
+		{cryptogram: CryptogramType(9), want: "CryptogramType(9)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.cryptogram.String(); got != tt.want {
+				t.Errorf("CryptogramType.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var unset Tlv
+	if unset.Cryptogram != CryptogramNone {
+		t.Errorf("Tlv{}.Cryptogram = %v, want %v", unset.Cryptogram, CryptogramNone)
+	}
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// Brand is the card scheme a PAN belongs to, as identified by its Issuer
+// Identification Number (BIN).
+type Brand int
+
+const (
+	BrandUnknown Brand = iota
+	BrandVisa
+	BrandMastercard
+	BrandAmex
+	BrandElo
+	BrandHipercard
+	BrandDiscover
+	BrandDinersClub
+	BrandJCB
+	BrandUnionPay
+	BrandMaestro
+)
+
+// Generic PAN length limits of ISO/IEC 7812, applied to unknown brands.
+const (
+	minPANLength = 13
+	maxPANLength = 19
+)
+
+type brandInfo struct {
+	name    string
+	lengths []int
+}
+
+var brands = map[Brand]brandInfo{
+	BrandVisa:       {name: "Visa", lengths: []int{13, 16, 19}},
+	BrandMastercard: {name: "Mastercard", lengths: []int{16}},
+	BrandAmex:       {name: "American Express", lengths: []int{15}},
+	BrandElo:        {name: "Elo", lengths: []int{16}},
+	BrandHipercard:  {name: "Hipercard", lengths: []int{13, 16, 19}},
+	BrandDiscover:   {name: "Discover", lengths: []int{16, 17, 18, 19}},
+	BrandDinersClub: {name: "Diners Club", lengths: []int{14, 15, 16, 17, 18, 19}},
+	BrandJCB:        {name: "JCB", lengths: []int{16, 17, 18, 19}},
+	BrandUnionPay:   {name: "UnionPay", lengths: []int{16, 17, 18, 19}},
+	BrandMaestro:    {name: "Maestro", lengths: []int{12, 13, 14, 15, 16, 17, 18, 19}},
+}
+
+// binRange matches the PANs whose first len(low) digits lie between low and
+// high inclusive. low and high have the same number of digits, so they
+// compare as strings.
+type binRange struct {
+	low, high string
+	brand     Brand
+}
+
+// binTable lists the BIN ranges of each brand. Ranges overlap, e.g. Elo
+// BINs fall within the Visa and Discover ranges; the longest matching
+// prefix wins.
+var binTable = []binRange{
+	{"4", "4", BrandVisa},
+
+	{"51", "55", BrandMastercard},
+	{"2221", "2720", BrandMastercard},
+
+	{"34", "34", BrandAmex},
+	{"37", "37", BrandAmex},
+
+	{"401178", "401179", BrandElo},
+	{"431274", "431274", BrandElo},
+	{"438935", "438935", BrandElo},
+	{"451416", "451416", BrandElo},
+	{"457393", "457393", BrandElo},
+	{"457631", "457632", BrandElo},
+	{"504175", "504175", BrandElo},
+	{"506699", "506778", BrandElo},
+	{"509000", "509999", BrandElo},
+	{"627780", "627780", BrandElo},
+	{"636297", "636297", BrandElo},
+	{"636368", "636368", BrandElo},
+	{"650031", "650033", BrandElo},
+	{"650035", "650051", BrandElo},
+	{"650405", "650439", BrandElo},
+	{"650485", "650538", BrandElo},
+	{"650541", "650598", BrandElo},
+	{"650700", "650718", BrandElo},
+	{"650720", "650727", BrandElo},
+	{"650901", "650978", BrandElo},
+	{"651652", "651679", BrandElo},
+	{"655000", "655019", BrandElo},
+	{"655021", "655058", BrandElo},
+
+	{"384100", "384100", BrandHipercard},
+	{"384140", "384140", BrandHipercard},
+	{"384160", "384160", BrandHipercard},
+	{"606282", "606282", BrandHipercard},
+	{"637095", "637095", BrandHipercard},
+	{"637568", "637568", BrandHipercard},
+	{"637599", "637599", BrandHipercard},
+	{"637609", "637609", BrandHipercard},
+	{"637612", "637612", BrandHipercard},
+
+	{"6011", "6011", BrandDiscover},
+	{"644", "649", BrandDiscover},
+	{"65", "65", BrandDiscover},
+
+	{"300", "305", BrandDinersClub},
+	{"36", "36", BrandDinersClub},
+	{"38", "39", BrandDinersClub},
+
+	{"3528", "3589", BrandJCB},
+
+	{"62", "62", BrandUnionPay},
+	{"8100", "8171", BrandUnionPay},
+
+	{"5018", "5018", BrandMaestro},
+	{"5020", "5020", BrandMaestro},
+	{"5038", "5038", BrandMaestro},
+	{"5893", "5893", BrandMaestro},
+	{"6304", "6304", BrandMaestro},
+	{"6759", "6759", BrandMaestro},
+	{"6761", "6763", BrandMaestro},
+}
+
+// LookupBrand returns the brand of pan from its BIN, or BrandUnknown when
+// no range matches.
+func LookupBrand(pan string) Brand {
+	brand := BrandUnknown
+	longest := 0
+
+	for _, r := range binTable {
+		n := len(r.low)
+		if n <= longest || len(pan) < n {
+			continue
+		}
+		if prefix := pan[:n]; prefix >= r.low && prefix <= r.high {
+			brand = r.brand
+			longest = n
+		}
+	}
+
+	return brand
+}
+
+// ValidLength reports whether a PAN of n digits is valid for the brand.
+// Unknown brands accept the generic 13 to 19 digits.
+func (b Brand) ValidLength(n int) bool {
+	info, ok := brands[b]
+	if !ok {
+		return n >= minPANLength && n <= maxPANLength
+	}
+	for _, length := range info.lengths {
+		if n == length {
+			return true
+		}
+	}
+	return false
+}
+
+func (b Brand) String() string {
+	if info, ok := brands[b]; ok {
+		return info.name
+	}
+	if b == BrandUnknown {
+		return "Unknown"
+	}
+	return fmt.Sprintf("Brand(%d)", int(b))
+}
+
+// Brand returns the brand of the card from its PAN.
+func (t *Tlv) Brand() Brand {
+	return LookupBrand(t.Pan)
+}
+
+// ValidatePanLength checks the PAN length against the rules of its brand.
+func (t *Tlv) ValidatePanLength() error {
+	brand := t.Brand()
+	if brand.ValidLength(len(t.Pan)) {
+		return nil
+	}
+	if brand == BrandUnknown {
+		return fmt.Errorf("PAN must be between %d and %d digits", minPANLength, maxPANLength)
+	}
+	return fmt.Errorf("invalid PAN length %d for %s: expected %s digits", len(t.Pan), brand, joinLengths(brands[brand].lengths))
+}
+
+// joinLengths formats lengths as "13, 16 or 19", or as a range when they
+// are consecutive.
+func joinLengths(lengths []int) string {
+	first, last := lengths[0], lengths[len(lengths)-1]
+	if len(lengths) > 2 && last-first == len(lengths)-1 {
+		return fmt.Sprintf("%d to %d", first, last)
+	}
+
+	s := fmt.Sprint(first)
+	for i, n := range lengths[1:] {
+		if i == len(lengths)-2 {
+			s += fmt.Sprintf(" or %d", n)
+		} else {
+			s += fmt.Sprintf(", %d", n)
+		}
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestLookupBrand(t *testing.T) {
+	tests := []struct {
+		name string
+		pan  string
+		want Brand
+	}{
+		{name: "Visa", pan: "4539578763621486", want: BrandVisa},
+		{name: "Mastercard 51-55", pan: "5555555555554444", want: BrandMastercard},
+		{name: "Mastercard 2-series", pan: "2223003122003222", want: BrandMastercard},
+		{name: "Mastercard 2-series upper bound", pan: "2720999999999999", want: BrandMastercard},
+		{name: "outside Mastercard 2-series", pan: "2721000000000000", want: BrandUnknown},
+		{name: "American Express", pan: "378282246310005", want: BrandAmex},
+		{name: "Elo within Visa range", pan: "4011780000000000", want: BrandElo},
+		{name: "Elo within Discover range", pan: "6504850000000000", want: BrandElo},
+		{name: "Elo 509 range", pan: "5090000000000000", want: BrandElo},
+		{name: "Hipercard", pan: "6062825624254001", want: BrandHipercard},
+		{name: "Hipercard within Diners range", pan: "3841000000000000", want: BrandHipercard},
+		{name: "Discover", pan: "6011111111111117", want: BrandDiscover},
+		{name: "Discover 65", pan: "6500000000000002", want: BrandDiscover},
+		{name: "Diners Club", pan: "30569309025904", want: BrandDinersClub},
+		{name: "JCB", pan: "3530111333300000", want: BrandJCB},
+		{name: "UnionPay", pan: "6200000000000005", want: BrandUnionPay},
+		{name: "Maestro", pan: "6759649826438453", want: BrandMaestro},
+		{name: "unknown", pan: "1234567890123456", want: BrandUnknown},
+		{name: "shorter than longer prefixes", pan: "4", want: BrandVisa},
+		{name: "empty", pan: "", want: BrandUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LookupBrand(tt.pan); got != tt.want {
+				t.Errorf("LookupBrand(%q) = %v, want %v", tt.pan, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTlv_ValidatePanLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		pan     string
+		wantErr string
+	}{
+		{name: "Visa 16 digits", pan: "4539578763621486"},
+		{name: "Visa 13 digits", pan: "4222222222222"},
+		{name: "Visa 15 digits", pan: "453957876362148", wantErr: "invalid PAN length 15 for Visa: expected 13, 16 or 19 digits"},
+		{name: "Mastercard 16 digits", pan: "5555555555554444"},
+		{name: "Mastercard 19 digits", pan: "5555555555554444000", wantErr: "invalid PAN length 19 for Mastercard: expected 16 digits"},
+		{name: "American Express 15 digits", pan: "378282246310005"},
+		{name: "American Express 16 digits", pan: "3782822463100050", wantErr: "invalid PAN length 16 for American Express: expected 15 digits"},
+		{name: "Diners Club 14 digits", pan: "30569309025904"},
+		{name: "JCB 15 digits", pan: "353011133330000", wantErr: "invalid PAN length 15 for JCB: expected 16 to 19 digits"},
+		{name: "Maestro 12 digits", pan: "675964982643"},
+		{name: "unknown brand 13 digits", pan: "1234567890123"},
+		{name: "unknown brand 12 digits", pan: "123456789012", wantErr: "PAN must be between 13 and 19 digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlv := &Tlv{Pan: tt.pan}
+			err := tlv.ValidatePanLength()
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Tlv.ValidatePanLength() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Tlv.ValidatePanLength() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBrand_String(t *testing.T) {
+	tests := []struct {
+		brand Brand
+		want  string
+	}{
+		{brand: BrandVisa, want: "Visa"},
+		{brand: BrandAmex, want: "American Express"},
+		{brand: BrandUnknown, want: "Unknown"},
+		{brand: Brand(99), want: "Brand(99)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.brand.String(); got != tt.want {
+				t.Errorf("Brand.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package domain
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/josuesantos1/emv/pkg/tlv"
+)
+
+// CVMMethod is the cardholder verification method code held in bits b6-b1
+// of the first byte of a CV Rule and of the CVM Results (9F34).
+type CVMMethod byte
+
+const (
+	CVMFailProcessing            CVMMethod = 0x00
+	CVMPlaintextPIN              CVMMethod = 0x01
+	CVMOnlinePIN                 CVMMethod = 0x02
+	CVMPlaintextPINAndSignature  CVMMethod = 0x03
+	CVMEncipheredPIN             CVMMethod = 0x04
+	CVMEncipheredPINAndSignature CVMMethod = 0x05
+	CVMSignature                 CVMMethod = 0x1E
+	CVMNoCVMRequired             CVMMethod = 0x1F
+	// CVMNotPerformed only appears in the CVM Results.
+	CVMNotPerformed CVMMethod = 0x3F
+)
+
+const (
+	cvmMethodMask    = 0x3F
+	cvmApplyNextMask = 0x40
+	cvmListHeaderLen = 8
+
+	// Byte 2 of the Terminal Capabilities (9F33), EMV 4.3 Book 4 Annex A2.
+	capabilitiesCVMByte       = 1
+	capabilitiesPlaintextPIN  = 0x80
+	capabilitiesOnlinePIN     = 0x40
+	capabilitiesSignature     = 0x20
+	capabilitiesEncipheredPIN = 0x10
+	capabilitiesNoCVM         = 0x08
+)
+
+// CVMCondition is the second byte of a CV Rule, stating when the rule applies.
+type CVMCondition byte
+
+const (
+	CVMAlways                 CVMCondition = 0x00
+	CVMIfUnattendedCash       CVMCondition = 0x01
+	CVMIfNotCash              CVMCondition = 0x02
+	CVMIfTerminalSupports     CVMCondition = 0x03
+	CVMIfManualCash           CVMCondition = 0x04
+	CVMIfPurchaseWithCashback CVMCondition = 0x05
+	CVMIfUnderX               CVMCondition = 0x06
+	CVMIfOverX                CVMCondition = 0x07
+	CVMIfUnderY               CVMCondition = 0x08
+	CVMIfOverY                CVMCondition = 0x09
+)
+
+// CVMRule is a Cardholder Verification Rule of the CVM List. When
+// ApplyNext is set a failed method moves on to the next rule instead of
+// failing cardholder verification.
+type CVMRule struct {
+	Method    CVMMethod
+	ApplyNext bool
+	Condition CVMCondition
+}
+
+// CVMList is the decoded Cardholder Verification Method List (tag 8E):
+// the amounts X and Y, in the application currency, followed by the rules
+// in priority order.
+type CVMList struct {
+	AmountX uint32
+	AmountY uint32
+	Rules   []CVMRule
+}
+
+func ParseCVMList(value []byte) (CVMList, error) {
+	if len(value) < cvmListHeaderLen {
+		return CVMList{}, fmt.Errorf("invalid CVM list: expected at least %d bytes, got %d", cvmListHeaderLen, len(value))
+	}
+	if (len(value)-cvmListHeaderLen)%2 != 0 {
+		return CVMList{}, fmt.Errorf("invalid CVM list: rules must be 2 bytes each, got %d bytes", len(value)-cvmListHeaderLen)
+	}
+
+	list := CVMList{
+		AmountX: binary.BigEndian.Uint32(value[0:4]),
+		AmountY: binary.BigEndian.Uint32(value[4:8]),
+	}
+
+	for i := cvmListHeaderLen; i < len(value); i += 2 {
+		list.Rules = append(list.Rules, CVMRule{
+			Method:    CVMMethod(value[i] & cvmMethodMask),
+			ApplyNext: value[i]&cvmApplyNextMask != 0,
+			Condition: CVMCondition(value[i+1]),
+		})
+	}
+
+	return list, nil
+}
+
+// UnmarshalBinary decodes the raw value of tag 8E.
+func (l *CVMList) UnmarshalBinary(value []byte) error {
+	parsed, err := ParseCVMList(value)
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// TransactionType is the kind of transaction, as far as CVM conditions
+// are concerned.
+type TransactionType int
+
+const (
+	TransactionPurchase TransactionType = iota
+	TransactionCash
+	TransactionPurchaseWithCashback
+)
+
+// PINResult is the outcome of asking the cardholder for a PIN.
+type PINResult int
+
+const (
+	// PINVerified means the PIN was entered and, for offline methods,
+	// verified by the card.
+	PINVerified PINResult = iota
+	// PINFailed means the card rejected the offline PIN.
+	PINFailed
+	// PINBypassed means the PIN pad works but no PIN was entered.
+	PINBypassed
+	// PINPadUnavailable means there is no working PIN pad.
+	PINPadUnavailable
+)
+
+// PINPad collects the cardholder PIN for a PIN based method.
+type PINPad interface {
+	EnterPIN(method CVMMethod) PINResult
+}
+
+// CVMContext is the transaction and terminal data CVM processing depends on.
+type CVMContext struct {
+	// Amount is the Amount, Authorised (9F02) in minor units.
+	Amount uint64
+	// Currency is the Transaction Currency Code (5F2A).
+	Currency uint16
+	// ApplicationCurrency is the Application Currency Code (9F42) of the card.
+	ApplicationCurrency uint16
+	Type                TransactionType
+	Unattended          bool
+	// Capabilities is the Terminal Capabilities (9F33); byte 2 lists
+	// the supported CVMs.
+	Capabilities [3]byte
+	// PINPad collects PINs; a nil PINPad behaves as PINPadUnavailable.
+	PINPad PINPad
+}
+
+// CVMOutcome is the result of CVM processing: the CVM Results (9F34) to
+// send online and the TVR bits set along the way.
+type CVMOutcome struct {
+	Results CVMResult
+	TVR     TVR
+}
+
+// Successful reports whether cardholder verification did not fail. The
+// verification may still be pending, as with signature or online PIN.
+func (o CVMOutcome) Successful() bool {
+	return o.Results.Result != CVMResultFailed
+}
+
+// Process performs cardholder verification as described in EMV 4.3 Book 3
+// §10.5: the first rule whose condition is satisfied and whose method the
+// terminal supports is performed; when it fails, processing continues with
+// the next rule only if the rule allows it.
+func (l *CVMList) Process(ctx CVMContext) CVMOutcome {
+	var outcome CVMOutcome
+
+	if l == nil || len(l.Rules) == 0 {
+		outcome.TVR.Set(tlv.TVRICCDataMissing)
+		outcome.Results = CVMResult{Method: CVMNotPerformed, FailIfUnsuccessful: true, Result: CVMResultUnknown}
+		return outcome
+	}
+
+	var last *CVMRule
+	for i := range l.Rules {
+		rule := l.Rules[i]
+		if !l.conditionSatisfied(rule, ctx) {
+			continue
+		}
+		last = &l.Rules[i]
+
+		if !rule.Method.recognised() {
+			outcome.TVR.Set(tlv.TVRUnrecognisedCVM)
+			if rule.ApplyNext {
+				continue
+			}
+			break
+		}
+
+		if rule.Method == CVMFailProcessing {
+			break
+		}
+
+		if !ctx.supports(rule.Method) {
+			if rule.ApplyNext {
+				continue
+			}
+			break
+		}
+
+		result, ok := outcome.perform(rule.Method, ctx)
+		if ok {
+			outcome.Results = rule.result(result)
+			return outcome
+		}
+		if !rule.ApplyNext {
+			break
+		}
+	}
+
+	outcome.TVR.Set(tlv.TVRCVMUnsuccessful)
+	if last == nil {
+		outcome.Results = CVMResult{Method: CVMNotPerformed, FailIfUnsuccessful: true, Result: CVMResultFailed}
+	} else {
+		outcome.Results = last.result(CVMResultFailed)
+	}
+	return outcome
+}
+
+func (l *CVMList) conditionSatisfied(rule CVMRule, ctx CVMContext) bool {
+	switch rule.Condition {
+	case CVMAlways:
+		return true
+	case CVMIfUnattendedCash:
+		return ctx.Type == TransactionCash && ctx.Unattended
+	case CVMIfNotCash:
+		return ctx.Type == TransactionPurchase
+	case CVMIfTerminalSupports:
+		return ctx.supports(rule.Method)
+	case CVMIfManualCash:
+		return ctx.Type == TransactionCash && !ctx.Unattended
+	case CVMIfPurchaseWithCashback:
+		return ctx.Type == TransactionPurchaseWithCashback
+	case CVMIfUnderX, CVMIfOverX, CVMIfUnderY, CVMIfOverY:
+		// Amount conditions only apply in the application currency.
+		if ctx.ApplicationCurrency == 0 || ctx.Currency != ctx.ApplicationCurrency {
+			return false
+		}
+		switch rule.Condition {
+		case CVMIfUnderX:
+			return ctx.Amount < uint64(l.AmountX)
+		case CVMIfOverX:
+			return ctx.Amount > uint64(l.AmountX)
+		case CVMIfUnderY:
+			return ctx.Amount < uint64(l.AmountY)
+		default:
+			return ctx.Amount > uint64(l.AmountY)
+		}
+	}
+	// Conditions the terminal does not understand are never satisfied.
+	return false
+}
+
+// perform carries out method and returns its result, or false when the
+// method failed.
+func (o *CVMOutcome) perform(method CVMMethod, ctx CVMContext) (CVMResultCode, bool) {
+	switch method {
+	case CVMNoCVMRequired:
+		return CVMResultSuccessful, true
+	case CVMSignature:
+		return CVMResultUnknown, true
+	}
+
+	pin := PINPadUnavailable
+	if ctx.PINPad != nil {
+		pin = ctx.PINPad.EnterPIN(method)
+	}
+
+	switch pin {
+	case PINPadUnavailable:
+		o.TVR.Set(tlv.TVRPINPadMissing)
+		return 0, false
+	case PINBypassed:
+		o.TVR.Set(tlv.TVRPINNotEntered)
+		return 0, false
+	case PINFailed:
+		return 0, false
+	}
+
+	switch method {
+	case CVMOnlinePIN:
+		o.TVR.Set(tlv.TVROnlinePINEntered)
+		return CVMResultUnknown, true
+	case CVMPlaintextPINAndSignature, CVMEncipheredPINAndSignature:
+		return CVMResultUnknown, true
+	}
+	return CVMResultSuccessful, true
+}
+
+func (m CVMMethod) recognised() bool {
+	switch m {
+	case CVMFailProcessing, CVMPlaintextPIN, CVMOnlinePIN, CVMPlaintextPINAndSignature,
+		CVMEncipheredPIN, CVMEncipheredPINAndSignature, CVMSignature, CVMNoCVMRequired:
+		return true
+	}
+	return false
+}
+
+// result returns the CVM Results for performing r with the given outcome.
+func (r CVMRule) result(code CVMResultCode) CVMResult {
+	return CVMResult{
+		Method:             r.Method,
+		FailIfUnsuccessful: !r.ApplyNext,
+		Condition:          r.Condition,
+		Result:             code,
+	}
+}
+
+// supports reports whether the terminal capabilities allow method.
+func (ctx CVMContext) supports(method CVMMethod) bool {
+	caps := ctx.Capabilities[capabilitiesCVMByte]
+	switch method {
+	case CVMFailProcessing:
+		return true
+	case CVMPlaintextPIN:
+		return caps&capabilitiesPlaintextPIN != 0
+	case CVMOnlinePIN:
+		return caps&capabilitiesOnlinePIN != 0
+	case CVMPlaintextPINAndSignature:
+		return caps&capabilitiesPlaintextPIN != 0 && caps&capabilitiesSignature != 0
+	case CVMEncipheredPIN:
+		return caps&capabilitiesEncipheredPIN != 0
+	case CVMEncipheredPINAndSignature:
+		return caps&capabilitiesEncipheredPIN != 0 && caps&capabilitiesSignature != 0
+	case CVMSignature:
+		return caps&capabilitiesSignature != 0
+	case CVMNoCVMRequired:
+		return caps&capabilitiesNoCVM != 0
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+)
+
+type stubPINPad PINResult
+
+func (p stubPINPad) EnterPIN(method CVMMethod) PINResult {
+	return PINResult(p)
+}
+
+func TestParseCVMList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    CVMList
+		wantErr bool
+	}{
+		{
+			name:  "amounts and two rules",
+			input: "000003E8000007D042031E00",
+			want: CVMList{
+				AmountX: 1000,
+				AmountY: 2000,
+				Rules: []CVMRule{
+					{Method: CVMOnlinePIN, ApplyNext: true, Condition: CVMIfTerminalSupports},
+					{Method: CVMSignature, Condition: CVMAlways},
+				},
+			},
+		},
+		{
+			name:  "amounts without rules",
+			input: "0000000000000000",
+			want:  CVMList{},
+		},
+		{
+			name:    "truncated amounts",
+			input:   "000003E8",
+			wantErr: true,
+		},
+		{
+			name:    "incomplete rule",
+			input:   "000003E8000007D042",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCVMList(hexToBytes(tt.input))
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCVMList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil {
+				if got.AmountX != tt.want.AmountX || got.AmountY != tt.want.AmountY {
+					t.Errorf("ParseCVMList() amounts = %d/%d, want %d/%d", got.AmountX, got.AmountY, tt.want.AmountX, tt.want.AmountY)
+				}
+				if fmt.Sprint(got.Rules) != fmt.Sprint(tt.want.Rules) {
+					t.Errorf("ParseCVMList() Rules = %v, want %v", got.Rules, tt.want.Rules)
+				}
+			}
+		})
+	}
+}
+
+func TestCVMList_Process(t *testing.T) {
+	tests := []struct {
+		name        string
+		list        string
+		ctx         CVMContext
+		wantResults string
+		wantTVR     string
+		wantSuccess bool
+	}{
+		{
+			name:        "signature always",
+			list:        "00000000000000001E00",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x28, 0xC8}},
+			wantResults: "1E0000",
+			wantTVR:     "0000000000",
+			wantSuccess: true,
+		},
+		{
+			name:        "online PIN without PIN pad falls back to signature",
+			list:        "000000000000000042031E03",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x60, 0xC8}},
+			wantResults: "1E0300",
+			wantTVR:     "0000100000",
+			wantSuccess: true,
+		},
+		{
+			name:        "online PIN entered",
+			list:        "000000000000000042031E03",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x60, 0xC8}, PINPad: stubPINPad(PINVerified)},
+			wantResults: "420300",
+			wantTVR:     "0000040000",
+			wantSuccess: true,
+		},
+		{
+			name:        "offline plaintext PIN verified",
+			list:        "00000000000000000100",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x80, 0xC8}, PINPad: stubPINPad(PINVerified)},
+			wantResults: "010002",
+			wantTVR:     "0000000000",
+			wantSuccess: true,
+		},
+		{
+			name:        "offline PIN rejected without next rule",
+			list:        "000000000000000001001F00",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x88, 0xC8}, PINPad: stubPINPad(PINFailed)},
+			wantResults: "010001",
+			wantTVR:     "0000800000",
+		},
+		{
+			name:        "PIN bypassed applies next rule",
+			list:        "000000000000000041001F00",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x88, 0xC8}, PINPad: stubPINPad(PINBypassed)},
+			wantResults: "1F0002",
+			wantTVR:     "0000080000",
+			wantSuccess: true,
+		},
+		{
+			name:        "unrecognised method fails verification",
+			list:        "00000000000000000800",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0xF8, 0xC8}},
+			wantResults: "080001",
+			wantTVR:     "0000C00000",
+		},
+		{
+			name:        "method not supported by terminal fails verification",
+			list:        "00000000000000000200",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x28, 0xC8}},
+			wantResults: "020001",
+			wantTVR:     "0000800000",
+		},
+		{
+			name:        "fail CVM processing rule",
+			list:        "00000000000000000000",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0xF8, 0xC8}},
+			wantResults: "000001",
+			wantTVR:     "0000800000",
+		},
+		{
+			name:        "no CVM under X in application currency",
+			list:        "000003E8000000005F061E00",
+			ctx:         CVMContext{Amount: 500, Currency: 986, ApplicationCurrency: 986, Capabilities: [3]byte{0xE0, 0x28, 0xC8}},
+			wantResults: "5F0602",
+			wantTVR:     "0000000000",
+			wantSuccess: true,
+		},
+		{
+			name:        "amount condition skipped in another currency",
+			list:        "000003E8000000005F061E00",
+			ctx:         CVMContext{Amount: 500, Currency: 840, ApplicationCurrency: 986, Capabilities: [3]byte{0xE0, 0x28, 0xC8}},
+			wantResults: "1E0000",
+			wantTVR:     "0000000000",
+			wantSuccess: true,
+		},
+		{
+			name:        "over Y",
+			list:        "000003E8000007D01E091F00",
+			ctx:         CVMContext{Amount: 2500, Currency: 986, ApplicationCurrency: 986, Capabilities: [3]byte{0xE0, 0x28, 0xC8}},
+			wantResults: "1E0900",
+			wantTVR:     "0000000000",
+			wantSuccess: true,
+		},
+		{
+			name:        "cash rule skipped for purchase",
+			list:        "00000000000000000204",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x60, 0xC8}},
+			wantResults: "3F0001",
+			wantTVR:     "0000800000",
+		},
+		{
+			name:        "empty list",
+			list:        "0000000000000000",
+			ctx:         CVMContext{Capabilities: [3]byte{0xE0, 0x28, 0xC8}},
+			wantResults: "3F0000",
+			wantTVR:     "2000000000",
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := ParseCVMList(hexToBytes(tt.list))
+			if err != nil {
+				t.Fatalf("ParseCVMList() unexpected error = %v", err)
+			}
+
+			got := list.Process(tt.ctx)
+
+			if results := got.Results.Hex(); results != tt.wantResults {
+				t.Errorf("CVMList.Process() Results = %s, want %s", results, tt.wantResults)
+			}
+			if tvr := got.TVR.Hex(); tvr != tt.wantTVR {
+				t.Errorf("CVMList.Process() TVR = %s, want %s", tvr, tt.wantTVR)
+			}
+			if got.Successful() != tt.wantSuccess {
+				t.Errorf("CVMList.Process() Successful() = %v, want %v", got.Successful(), tt.wantSuccess)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+)
+
+const (
+	cvmResultsLen = 3
+
+	cvmPaymentSystemFirst CVMMethod = 0x20
+	cvmIssuerLast         CVMMethod = 0x3E
+)
+
+// CVMResultCode is the third byte of the CVM Results: the outcome of the
+// method performed.
+type CVMResultCode byte
+
+const (
+	CVMResultUnknown    CVMResultCode = 0x00
+	CVMResultFailed     CVMResultCode = 0x01
+	CVMResultSuccessful CVMResultCode = 0x02
+)
+
+// CVMResult is the decoded CVM Results (tag 9F34): the method performed,
+// the condition of the CV Rule it came from and its outcome.
+// FailIfUnsuccessful is set when the rule did not allow moving on to the
+// next rule (b7 of the first byte clear).
+type CVMResult struct {
+	Method             CVMMethod
+	FailIfUnsuccessful bool
+	Condition          CVMCondition
+	Result             CVMResultCode
+}
+
+func ParseCVMResult(value []byte) (CVMResult, error) {
+	if len(value) != cvmResultsLen {
+		return CVMResult{}, fmt.Errorf("invalid CVM results: expected %d bytes, got %d", cvmResultsLen, len(value))
+	}
+
+	return CVMResult{
+		Method:             CVMMethod(value[0] & cvmMethodMask),
+		FailIfUnsuccessful: value[0]&cvmApplyNextMask == 0,
+		Condition:          CVMCondition(value[1]),
+		Result:             CVMResultCode(value[2]),
+	}, nil
+}
+
+// UnmarshalBinary decodes the raw value of tag 9F34.
+func (r *CVMResult) UnmarshalBinary(value []byte) error {
+	parsed, err := ParseCVMResult(value)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalBinary encodes r as the value of tag 9F34.
+func (r CVMResult) MarshalBinary() ([]byte, error) {
+	return r.Bytes(), nil
+}
+
+func (r CVMResult) Bytes() []byte {
+	b := byte(r.Method) & cvmMethodMask
+	if !r.FailIfUnsuccessful {
+		b |= cvmApplyNextMask
+	}
+	return []byte{b, byte(r.Condition), byte(r.Result)}
+}
+
+// Hex returns the 9F34 value as upper case hex, e.g. "1E0300".
+func (r CVMResult) Hex() string {
+	return fmt.Sprintf("%X", r.Bytes())
+}
+
+func (r CVMResult) String() string {
+	return fmt.Sprintf("%s, %s: %s", r.Method, r.Condition, r.Result)
+}
+
+// Validate checks that every byte holds a value defined by EMV 4.3 Book 3
+// Annex C3. Methods reserved for payment systems and issuers are accepted.
+func (r CVMResult) Validate() error {
+	if !r.Method.recognised() && r.Method != CVMNotPerformed &&
+		(r.Method < cvmPaymentSystemFirst || r.Method > cvmIssuerLast) {
+		return fmt.Errorf("invalid CVM method 0x%02X: not a supported method", byte(r.Method))
+	}
+	if r.Condition > CVMIfOverY {
+		return fmt.Errorf("invalid CVM condition 0x%02X: not a supported condition", byte(r.Condition))
+	}
+	if r.Result > CVMResultSuccessful {
+		return fmt.Errorf("invalid CVM result 0x%02X: not a supported value", byte(r.Result))
+	}
+	return nil
+}
+
+// IsPIN reports whether m verifies a PIN, offline or online.
+func (m CVMMethod) IsPIN() bool {
+	return m >= CVMPlaintextPIN && m <= CVMEncipheredPINAndSignature
+}
+
+var cvmMethodNames = map[CVMMethod]string{
+	CVMFailProcessing:            "Fail CVM processing",
+	CVMPlaintextPIN:              "Plaintext PIN verified by ICC",
+	CVMOnlinePIN:                 "Enciphered PIN verified online",
+	CVMPlaintextPINAndSignature:  "Plaintext PIN verified by ICC and signature",
+	CVMEncipheredPIN:             "Enciphered PIN verified by ICC",
+	CVMEncipheredPINAndSignature: "Enciphered PIN verified by ICC and signature",
+	CVMSignature:                 "Signature",
+	CVMNoCVMRequired:             "No CVM required",
+	CVMNotPerformed:              "No CVM performed",
+}
+
+func (m CVMMethod) String() string {
+	if name, ok := cvmMethodNames[m]; ok {
+		return name
+	}
+	if m >= cvmPaymentSystemFirst && m <= cvmIssuerLast {
+		return fmt.Sprintf("Proprietary method 0x%02X", byte(m))
+	}
+	return fmt.Sprintf("Unknown method 0x%02X", byte(m))
+}
+
+var cvmConditionNames = map[CVMCondition]string{
+	CVMAlways:                 "always",
+	CVMIfUnattendedCash:       "if unattended cash",
+	CVMIfNotCash:              "if not unattended cash, manual cash or cashback",
+	CVMIfTerminalSupports:     "if terminal supports the CVM",
+	CVMIfManualCash:           "if manual cash",
+	CVMIfPurchaseWithCashback: "if purchase with cashback",
+	CVMIfUnderX:               "if under X in application currency",
+	CVMIfOverX:                "if over X in application currency",
+	CVMIfUnderY:               "if under Y in application currency",
+	CVMIfOverY:                "if over Y in application currency",
+}
+
+func (c CVMCondition) String() string {
+	if name, ok := cvmConditionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown condition 0x%02X", byte(c))
+}
+
+func (c CVMResultCode) String() string {
+	switch c {
+	case CVMResultUnknown:
+		return "unknown"
+	case CVMResultFailed:
+		return "failed"
+	case CVMResultSuccessful:
+		return "successful"
+	}
+	return fmt.Sprintf("unknown result 0x%02X", byte(c))
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestParseCVMResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		want       CVMResult
+		wantString string
+		wantErr    bool
+	}{
+		{
+			name:       "signature",
+			input:      "1E0300",
+			want:       CVMResult{Method: CVMSignature, FailIfUnsuccessful: true, Condition: CVMIfTerminalSupports, Result: CVMResultUnknown},
+			wantString: "Signature, if terminal supports the CVM: unknown",
+		},
+		{
+			name:       "online PIN with apply next",
+			input:      "420000",
+			want:       CVMResult{Method: CVMOnlinePIN, Condition: CVMAlways, Result: CVMResultUnknown},
+			wantString: "Enciphered PIN verified online, always: unknown",
+		},
+		{
+			name:       "offline plaintext PIN successful",
+			input:      "010602",
+			want:       CVMResult{Method: CVMPlaintextPIN, FailIfUnsuccessful: true, Condition: CVMIfUnderX, Result: CVMResultSuccessful},
+			wantString: "Plaintext PIN verified by ICC, if under X in application currency: successful",
+		},
+		{
+			name:       "no CVM performed",
+			input:      "3F0001",
+			want:       CVMResult{Method: CVMNotPerformed, FailIfUnsuccessful: true, Condition: CVMAlways, Result: CVMResultFailed},
+			wantString: "No CVM performed, always: failed",
+		},
+		{
+			name:    "too short",
+			input:   "1F00",
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			input:   "1F000200",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCVMResult(hexToBytes(tt.input))
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCVMResult() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil {
+				if got != tt.want {
+					t.Errorf("ParseCVMResult() = %+v, want %+v", got, tt.want)
+				}
+				if got.String() != tt.wantString {
+					t.Errorf("CVMResult.String() = %q, want %q", got.String(), tt.wantString)
+				}
+				if got.Hex() != tt.input {
+					t.Errorf("CVMResult.Hex() = %v, want %v", got.Hex(), tt.input)
+				}
+			}
+		})
+	}
+}
+
+func TestCVMResult_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "no CVM required", input: "1F0002", wantErr: false},
+		{name: "proprietary method", input: "200000", wantErr: false},
+		{name: "issuer method", input: "3E0000", wantErr: false},
+		{name: "RFU method", input: "080000", wantErr: true},
+		{name: "unknown condition", input: "1F0A02", wantErr: true},
+		{name: "unknown result", input: "1F0003", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseCVMResult(hexToBytes(tt.input))
+			if err != nil {
+				t.Fatalf("ParseCVMResult() unexpected error = %v", err)
+			}
+			if err := r.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("CVMResult.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/josuesantos1/emv/pkg/iso"
+)
+
+// DefaultCurrencyExponent is used when the card data carries no
+// Transaction Currency Exponent (5F36) and the currency is unknown.
+const DefaultCurrencyExponent = 2
+
+// Money is an amount in the minor units of an ISO 4217 currency. Exponent
+// is the number of minor unit digits, e.g. 2 for BRL and 0 for JPY.
+type Money struct {
+	Amount   uint64
+	Currency uint16
+	Exponent int
+}
+
+// Decimal formats the amount in major units, e.g. "12.50".
+func (m Money) Decimal() string {
+	return iso.FormatAmount(m.Amount, m.Exponent)
+}
+
+// CurrencyCode returns the ISO 4217 numeric code as three digits.
+func (m Money) CurrencyCode() string {
+	return fmt.Sprintf("%03d", m.Currency)
+}
+
+// CurrencyAlpha returns the ISO 4217 alphabetic code, e.g. "BRL", or the
+// numeric code when the currency is unknown.
+func (m Money) CurrencyAlpha() string {
+	if c, ok := iso.LookupCurrency(m.Currency); ok {
+		return c.Alpha
+	}
+	return m.CurrencyCode()
+}
+
+// String formats the amount with its currency, e.g. "BRL 12.50".
+func (m Money) String() string {
+	return m.CurrencyAlpha() + " " + m.Decimal()
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "testing"
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		name        string
+		money       Money
+		wantDecimal string
+		wantString  string
+	}{
+		{name: "two decimals", money: Money{Amount: 1250, Currency: 986, Exponent: 2}, wantDecimal: "12.50", wantString: "BRL 12.50"},
+		{name: "less than one unit", money: Money{Amount: 5, Currency: 840, Exponent: 2}, wantDecimal: "0.05", wantString: "USD 0.05"},
+		{name: "zero amount", money: Money{Currency: 986, Exponent: 2}, wantDecimal: "0.00", wantString: "BRL 0.00"},
+		{name: "no minor units", money: Money{Amount: 1250, Currency: 392, Exponent: 0}, wantDecimal: "1250", wantString: "JPY 1250"},
+		{name: "unknown currency", money: Money{Amount: 1250, Currency: 1, Exponent: 2}, wantDecimal: "12.50", wantString: "001 12.50"},
+		{name: "three decimals", money: Money{Amount: 1250, Currency: 48, Exponent: 3}, wantDecimal: "1.250", wantString: "BHD 1.250"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Decimal(); got != tt.wantDecimal {
+				t.Errorf("Money.Decimal() = %v, want %v", got, tt.wantDecimal)
+			}
+			if got := tt.money.String(); got != tt.wantString {
+				t.Errorf("Money.String() = %v, want %v", got, tt.wantString)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Interchange is the first service code digit: where the card may be used
+// and whether the chip must be used when present.
+type Interchange byte
+
+const (
+	InterchangeInternational   Interchange = 1
+	InterchangeInternationalIC Interchange = 2
+	InterchangeNational        Interchange = 5
+	InterchangeNationalIC      Interchange = 6
+	InterchangePrivate         Interchange = 7
+	InterchangeTest            Interchange = 9
+)
+
+// Authorization is the second service code digit: how transactions must
+// be authorized.
+type Authorization byte
+
+const (
+	AuthorizationNormal                  Authorization = 0
+	AuthorizationByIssuer                Authorization = 2
+	AuthorizationByIssuerUnlessBilateral Authorization = 4
+)
+
+// Services is the third service code digit: which services are allowed
+// and whether a PIN is required.
+type Services byte
+
+const (
+	ServicesNoRestrictionsPINRequired   Services = 0
+	ServicesNoRestrictions              Services = 1
+	ServicesGoodsAndServices            Services = 2
+	ServicesATMOnlyPINRequired          Services = 3
+	ServicesCashOnly                    Services = 4
+	ServicesGoodsAndServicesPINRequired Services = 5
+	ServicesNoRestrictionsPromptPIN     Services = 6
+	ServicesGoodsAndServicesPromptPIN   Services = 7
+)
+
+// ServiceCode is the three digit service code from tag 5F30 or Track 2.
+type ServiceCode struct {
+	Interchange   Interchange
+	Authorization Authorization
+	Services      Services
+}
+
+func ParseServiceCode(code string) (ServiceCode, error) {
+	if len(code) != 3 || !isDigits(code) {
+		return ServiceCode{}, fmt.Errorf("invalid service code '%s': expected 3 digits", code)
+	}
+
+	sc := ServiceCode{
+		Interchange:   Interchange(code[0] - '0'),
+		Authorization: Authorization(code[1] - '0'),
+		Services:      Services(code[2] - '0'),
+	}
+
+	switch sc.Interchange {
+	case InterchangeInternational, InterchangeInternationalIC, InterchangeNational,
+		InterchangeNationalIC, InterchangePrivate, InterchangeTest:
+	default:
+		return ServiceCode{}, fmt.Errorf("invalid service code '%s': unsupported interchange digit %d", code, sc.Interchange)
+	}
+
+	switch sc.Authorization {
+	case AuthorizationNormal, AuthorizationByIssuer, AuthorizationByIssuerUnlessBilateral:
+	default:
+		return ServiceCode{}, fmt.Errorf("invalid service code '%s': unsupported authorization digit %d", code, sc.Authorization)
+	}
+
+	if sc.Services > ServicesGoodsAndServicesPromptPIN {
+		return ServiceCode{}, fmt.Errorf("invalid service code '%s': unsupported services digit %d", code, sc.Services)
+	}
+
+	return sc, nil
+}
+
+// UnmarshalBinary decodes the n3 value of tag 5F30.
+func (s *ServiceCode) UnmarshalBinary(value []byte) error {
+	digits := strings.TrimPrefix(fmt.Sprintf("%X", value), "0")
+	parsed, err := ParseServiceCode(digits)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+func (s ServiceCode) String() string {
+	return fmt.Sprintf("%d%d%d", s.Interchange, s.Authorization, s.Services)
+}
+
+// OnlineRequired reports whether the issuer must authorize every transaction.
+func (s ServiceCode) OnlineRequired() bool {
+	return s.Authorization == AuthorizationByIssuer || s.Authorization == AuthorizationByIssuerUnlessBilateral
+}
+
+// PINRequired reports whether the cardholder must enter a PIN.
+func (s ServiceCode) PINRequired() bool {
+	switch s.Services {
+	case ServicesNoRestrictionsPINRequired, ServicesATMOnlyPINRequired, ServicesGoodsAndServicesPINRequired:
+		return true
+	}
+	return false
+}
+
+// PromptPIN reports whether a PIN should be requested when a PIN pad is present.
+func (s ServiceCode) PromptPIN() bool {
+	return s.Services == ServicesNoRestrictionsPromptPIN || s.Services == ServicesGoodsAndServicesPromptPIN
+}
+
+// AllowsPurchase reports whether the card may be used for a purchase at a POS.
+func (s ServiceCode) AllowsPurchase() bool {
+	return s.Services != ServicesATMOnlyPINRequired && s.Services != ServicesCashOnly
+}
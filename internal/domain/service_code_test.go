@@ -0,0 +1,70 @@
+package domain
+
+import "testing"
+
+func TestParseServiceCode(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		wantOnlineRequired bool
+		wantPINRequired    bool
+		wantPromptPIN      bool
+		wantPurchase       bool
+		wantErr            bool
+	}{
+		{name: "international, normal, no restrictions", input: "101", wantPurchase: true},
+		{name: "international IC, normal, no restrictions", input: "201", wantPurchase: true},
+		{name: "issuer authorization, PIN required", input: "220", wantOnlineRequired: true, wantPINRequired: true, wantPurchase: true},
+		{name: "national, issuer unless bilateral, prompt PIN", input: "546", wantOnlineRequired: true, wantPromptPIN: true, wantPurchase: true},
+		{name: "ATM only", input: "103", wantPINRequired: true, wantPurchase: false},
+		{name: "cash only", input: "104", wantPurchase: false},
+		{name: "invalid interchange digit", input: "301", wantErr: true},
+		{name: "invalid authorization digit", input: "111", wantErr: true},
+		{name: "invalid services digit", input: "108", wantErr: true},
+		{name: "too short", input: "20", wantErr: true},
+		{name: "not numeric", input: "2A1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseServiceCode(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseServiceCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil {
+				if got.String() != tt.input {
+					t.Errorf("ServiceCode.String() = %v, want %v", got.String(), tt.input)
+				}
+				if got.OnlineRequired() != tt.wantOnlineRequired {
+					t.Errorf("ServiceCode.OnlineRequired() = %v, want %v", got.OnlineRequired(), tt.wantOnlineRequired)
+				}
+				if got.PINRequired() != tt.wantPINRequired {
+					t.Errorf("ServiceCode.PINRequired() = %v, want %v", got.PINRequired(), tt.wantPINRequired)
+				}
+				if got.PromptPIN() != tt.wantPromptPIN {
+					t.Errorf("ServiceCode.PromptPIN() = %v, want %v", got.PromptPIN(), tt.wantPromptPIN)
+				}
+				if got.AllowsPurchase() != tt.wantPurchase {
+					t.Errorf("ServiceCode.AllowsPurchase() = %v, want %v", got.AllowsPurchase(), tt.wantPurchase)
+				}
+			}
+		})
+	}
+}
+
+func TestServiceCode_UnmarshalBinary(t *testing.T) {
+	var sc ServiceCode
+	if err := sc.UnmarshalBinary(hexToBytes("0201")); err != nil {
+		t.Fatalf("ServiceCode.UnmarshalBinary() unexpected error = %v", err)
+	}
+	if sc.String() != "201" {
+		t.Errorf("ServiceCode.UnmarshalBinary() = %v, want 201", sc)
+	}
+
+	if err := sc.UnmarshalBinary(hexToBytes("1201")); err == nil {
+		t.Errorf("ServiceCode.UnmarshalBinary() expected error for 4 digit value")
+	}
+}
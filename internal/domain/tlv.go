@@ -3,93 +3,346 @@ package domain
 import (
 	"encoding/hex"
 	"fmt"
+	"github.com/josuesantos1/emv/pkg/iso"
 	"github.com/josuesantos1/emv/pkg/tlv"
 	"time"
 )
 
 type Tlv struct {
-	Pan          string
-	DataValidade time.Time
-	CVM          string
+	Pan          string       `emv:"5A,cn"`
+	DataValidade time.Time    `emv:"5F24,n,date"`
+	DataEfetiva  time.Time    `emv:"5F25,n,date"`
+	CVM          *CVMResult   `emv:"9F34,b"`
+	ServiceCode  *ServiceCode `emv:"5F30,n"`
+	CVMList      *CVMList     `emv:"8E,b"`
+
+	// ApplicationCurrency is the Application Currency Code (9F42) the CVM
+	// List amounts are expressed in.
+	ApplicationCurrency uint16 `emv:"9F42,n"`
+
+	// Transaction amounts in minor units of TransactionCurrency, an ISO
+	// 4217 numeric code. CurrencyExponent is nil when 5F36 is absent.
+	AmountAuthorised    uint64 `emv:"9F02,n"`
+	AmountOther         uint64 `emv:"9F03,n"`
+	TransactionCurrency uint16 `emv:"5F2A,n"`
+	CurrencyExponent    *uint8 `emv:"5F36,n"`
+
+	// TerminalCountry is the ISO 3166 numeric country code (9F1A).
+	TerminalCountry uint16 `emv:"9F1A,n"`
+
+	// PANSequenceNumber is the Application PAN Sequence Number (5F34);
+	// nil when absent.
+	PANSequenceNumber *uint8 `emv:"5F34,n"`
+
+	// Velocity checking data; nil when absent from the card data.
+	ATC               *uint16 `emv:"9F36,b"`
+	LastOnlineATC     *uint16 `emv:"9F13,b"`
+	LowerOfflineLimit *uint8  `emv:"9F14,b"`
+	UpperOfflineLimit *uint8  `emv:"9F23,b"`
+
+	// Issuer Action Codes; nil when absent from the card data.
+	IACDefault *ActionCode `emv:"9F0D,b"`
+	IACDenial  *ActionCode `emv:"9F0E,b"`
+	IACOnline  *ActionCode `emv:"9F0F,b"`
+
+	// Track2 is decoded by Populate so that it honours the century window.
+	Track2 *Track2
+
+	// Conflicts lists the tags repeated anywhere in the card data.
+	Conflicts []tlv.Conflict
+
+	// TVR and TSI record the checks run while processing the transaction
+	// and their outcome. Validate sets the bits for the checks it runs.
+	TVR TVR
+	TSI TSI
+
+	// Cryptogram is the Application Cryptogram requested after terminal
+	// action analysis.
+	Cryptogram CryptogramType
+}
+
+// PopulateOption configures Populate.
+type PopulateOption func(*populateConfig)
+
+type populateConfig struct {
+	duplicates   tlv.DuplicatePolicy
+	centuryPivot int
+}
+
+// WithDuplicatePolicy selects which occurrence of a repeated tag is used,
+// or rejects the card data altogether. The default is last-wins.
+func WithDuplicatePolicy(policy tlv.DuplicatePolicy) PopulateOption {
+	return func(c *populateConfig) {
+		c.duplicates = policy
+	}
+}
+
+// WithCenturyPivot sets the century window used for two-digit years in
+// the expiry and effective dates: years below pivot are read as 20YY and
+// the others as 19YY. The default is tlv.DefaultCenturyPivot.
+func WithCenturyPivot(pivot int) PopulateOption {
+	return func(c *populateConfig) {
+		c.centuryPivot = pivot
+	}
 }
 
 const (
-	Pan     = "5A"
-	ExpDate = "5F24"
-	CVM     = "9F34"
+	Pan     = tlv.TagApplicationPAN
+	ExpDate = tlv.TagExpirationDate
+	CVM     = tlv.TagCVMResults
 )
 
-func (t *Tlv) Populate(tlvs []tlv.TLV) error {
-	for _, tlvItem := range tlvs {
-		tagHex := tlvItem.TagHex()
-		valueHex := tlvItem.ValueHex()
+func (t *Tlv) Populate(tlvs []tlv.TLV, opts ...PopulateOption) error {
+	cfg := populateConfig{centuryPivot: tlv.DefaultCenturyPivot}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
-		if tagHex == Pan {
-			t.Pan = valueHex
-		}
+	t.Conflicts = tlv.Duplicates(tlvs)
 
-		if tagHex == ExpDate {
-			if len(valueHex) < 4 {
-				return fmt.Errorf("invalid expiry date format: value too short")
-			}
-			yy := valueHex[0:2]
-			mm := valueHex[2:4]
-			dd := "01"
-			year := "20" + yy
+	unmarshal := tlv.UnmarshalOptions{Duplicates: cfg.duplicates, CenturyPivot: cfg.centuryPivot}
+	if err := unmarshal.UnmarshalTLVs(tlvs, t); err != nil {
+		return err
+	}
 
-			dateStr := fmt.Sprintf("%s-%s-%s", year, mm, dd)
+	if item, ok := unmarshal.Lookup(tlvs, tlv.TagTrack2); ok {
+		track2, err := ParseTrack2(item.Value, cfg.centuryPivot)
+		if err != nil {
+			return fmt.Errorf("tag %s: %w", tlv.TagTrack2, err)
+		}
+		t.Track2 = &track2
+	}
 
-			parsedTime, err := time.Parse("2006-01-02", dateStr)
+	// Contactless cards may deliver PAN and expiry only inside Track 2.
+	if t.Track2 != nil {
+		if t.Pan == "" {
+			t.Pan = t.Track2.Pan
+		}
+		if t.DataValidade.IsZero() {
+			t.DataValidade = t.Track2.DataValidade
+		}
+		if t.ServiceCode == nil {
+			sc, err := ParseServiceCode(t.Track2.ServiceCode)
 			if err != nil {
-				return fmt.Errorf("failed to parse expiry date '%s': %w", dateStr, err)
+				return fmt.Errorf("track 2: %w", err)
 			}
-
-			t.DataValidade = parsedTime
-		}
-
-		if tagHex == CVM {
-			t.CVM = valueHex
+			t.ServiceCode = &sc
 		}
 	}
 
 	return nil
 }
 
-func (t *Tlv) Validate() error {
+// CheckError reports card data that is well formed but fails one of the
+// card checks of Validate, such as an expired application. The TVR bit of
+// the check is set on the transaction, which is to be declined rather than
+// terminated as for malformed or missing mandatory data.
+type CheckError struct {
+	Bit    tlv.Bit
+	Reason string
+}
+
+func (e *CheckError) Error() string {
+	return e.Reason
+}
+
+// fail sets bit in the TVR and returns the CheckError for it.
+func (t *Tlv) fail(bit tlv.Bit, format string, args ...any) error {
+	t.TVR.Set(bit)
+	return &CheckError{Bit: bit, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks the card data for a transaction taking place at now,
+// as ValidateCard followed by ValidateCardholder. Failed card checks are
+// reported as *CheckError; any other error means the card data is
+// malformed or lacks mandatory data.
+func (t *Tlv) Validate(now time.Time) error {
+	if err := t.ValidateCard(now); err != nil {
+		return err
+	}
+	return t.ValidateCardholder()
+}
+
+// ValidateCard runs the card checks that do not depend on cardholder
+// verification, so a card to be rejected never reaches the PIN pad.
+// Expiry and effective dates are compared with the calendar date of now
+// in its own location, so the terminal time zone decides the day. The CVM
+// Results (9F34) may be absent when the card has a CVM List to process.
+func (t *Tlv) ValidateCard(now time.Time) error {
+	// PAN and expiry date are mandatory: without them the transaction is
+	// terminated rather than declined.
 	if t.Pan == "" {
 		return fmt.Errorf("field Pan is required")
 	}
 	if t.DataValidade.IsZero() {
 		return fmt.Errorf("field Data de validade is required")
 	}
-	if t.CVM == "" {
+	if t.CVM == nil && t.CVMList == nil {
 		return fmt.Errorf("field CVM is required")
 	}
 
-	if len(t.Pan) < 13 || len(t.Pan) > 19 {
-		return fmt.Errorf("PAN must be between 13 and 19 digits")
+	if err := t.ValidateTrack2(); err != nil {
+		return err
+	}
+
+	if err := t.ValidatePanLength(); err != nil {
+		return err
 	}
 
 	if !t.ValidatePan() {
 		return fmt.Errorf("PAN failed Luhn algorithm validation")
 	}
 
-	now := time.Now()
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	// The expiry date is the last day on which the card may be used.
+	if today.After(t.DataValidade) {
+		return t.fail(tlv.TVRExpiredApplication, "card expired: expiry date %s is before current date %s", t.DataValidade.Format("2006-01-02"), today.Format("2006-01-02"))
+	}
+
+	if !t.DataEfetiva.IsZero() && today.Before(t.DataEfetiva) {
+		return t.fail(tlv.TVRNotYetEffective, "card not yet effective: effective date %s is after current date %s", t.DataEfetiva.Format("2006-01-02"), today.Format("2006-01-02"))
+	}
+
+	if t.CVM != nil {
+		if err := t.CVM.Validate(); err != nil {
+			return err
+		}
+	}
 
-	year := t.DataValidade.Year()
-	month := t.DataValidade.Month()
+	if err := t.ValidateAmount(); err != nil {
+		return err
+	}
 
-	if year < now.Year() || (year == now.Year() && month < now.Month()) {
-		return fmt.Errorf("card expired: expiry date %s is before current date %s", t.DataValidade.Format("01/2006"), now.Format("01/2006"))
+	if err := t.ValidateServiceCode(); err != nil {
+		return err
 	}
 
+	return nil
+}
+
+// ValidateCardholder checks the outcome of cardholder verification: the
+// CVM Results read from the card or produced by CVM List processing, and
+// the PIN verification a service code may require.
+func (t *Tlv) ValidateCardholder() error {
 	if err := t.ValidateCVM(); err != nil {
 		return err
 	}
 
+	if t.ServiceCode != nil && t.ServiceCode.PINRequired() && !t.pinVerified() {
+		return t.fail(tlv.TVRCVMUnsuccessful, "service code %s requires PIN verification, CVM performed was %s", t.ServiceCode, t.CVM)
+	}
+
 	return nil
 }
 
+// IssuerActionCodes returns the Issuer Action Codes read from the card.
+// An absent IAC-Denial selects no bit while absent IAC-Online and
+// IAC-Default select every bit, as required by EMV 4.3 Book 3 §10.7.
+func (t *Tlv) IssuerActionCodes() ActionCodes {
+	codes := ActionCodes{
+		Denial:  ActionCodeNone,
+		Online:  ActionCodeAll,
+		Default: ActionCodeAll,
+	}
+	if t.IACDenial != nil {
+		codes.Denial = *t.IACDenial
+	}
+	if t.IACOnline != nil {
+		codes.Online = *t.IACOnline
+	}
+	if t.IACDefault != nil {
+		codes.Default = *t.IACDefault
+	}
+	return codes
+}
+
+// Amount returns the Amount, Authorised (9F02) in the transaction currency.
+func (t *Tlv) Amount() Money {
+	return t.money(t.AmountAuthorised)
+}
+
+// OtherAmount returns the Amount, Other (9F03), the cashback part of the
+// authorised amount.
+func (t *Tlv) OtherAmount() Money {
+	return t.money(t.AmountOther)
+}
+
+// money uses the exponent from 5F36 when present, falling back to the
+// minor units of the ISO 4217 currency.
+func (t *Tlv) money(amount uint64) Money {
+	exponent := DefaultCurrencyExponent
+	if t.CurrencyExponent != nil {
+		exponent = int(*t.CurrencyExponent)
+	} else if c, ok := iso.LookupCurrency(t.TransactionCurrency); ok {
+		exponent = c.MinorUnits
+	}
+	return Money{Amount: amount, Currency: t.TransactionCurrency, Exponent: exponent}
+}
+
+// ValidateAmount checks that amounts come with a known ISO 4217 currency
+// and that the cashback amount is part of the authorised amount.
+func (t *Tlv) ValidateAmount() error {
+	if (t.AmountAuthorised > 0 || t.AmountOther > 0) && t.TransactionCurrency == 0 {
+		return fmt.Errorf("transaction currency (5F2A) is required with an amount")
+	}
+	if t.TransactionCurrency != 0 {
+		if _, ok := iso.LookupCurrency(t.TransactionCurrency); !ok {
+			return fmt.Errorf("unknown transaction currency code %03d", t.TransactionCurrency)
+		}
+	}
+	if t.AmountOther > t.AmountAuthorised {
+		return fmt.Errorf("amount other %s exceeds amount authorised %s", t.OtherAmount().Decimal(), t.Amount().Decimal())
+	}
+	return nil
+}
+
+// ValidateTrack2 checks that Track 2 agrees with the PAN (5A) and
+// expiry date (5F24) read from the card.
+func (t *Tlv) ValidateTrack2() error {
+	if t.Track2 == nil {
+		return nil
+	}
+
+	if t.Track2.Pan != t.Pan {
+		return fmt.Errorf("track 2 PAN does not match application PAN")
+	}
+
+	if t.ServiceCode != nil && t.ServiceCode.String() != t.Track2.ServiceCode {
+		return fmt.Errorf("track 2 service code %s does not match service code %s", t.Track2.ServiceCode, t.ServiceCode)
+	}
+
+	year, month, _ := t.DataValidade.Date()
+	track2Year, track2Month, _ := t.Track2.DataValidade.Date()
+	if year != track2Year || month != track2Month {
+		return fmt.Errorf("track 2 expiry date %s does not match application expiry date %s", t.Track2.DataValidade.Format("01/2006"), t.DataValidade.Format("01/2006"))
+	}
+
+	return nil
+}
+
+// ValidateServiceCode rejects cards whose service code does not allow a
+// purchase at a POS terminal, such as ATM-only and cash-only cards. The PIN
+// a service code may require is checked by ValidateCardholder.
+func (t *Tlv) ValidateServiceCode() error {
+	if t.ServiceCode != nil && !t.ServiceCode.AllowsPurchase() {
+		return t.fail(tlv.TVRServiceNotAllowed, "service code %s does not allow purchases at a POS terminal", t.ServiceCode)
+	}
+	return nil
+}
+
+// pinVerified reports whether a PIN method was performed without failing.
+// A PIN verified by the card reports success; an online PIN, or a PIN
+// combined with signature, reports unknown until the issuer or the
+// merchant completes verification.
+func (t *Tlv) pinVerified() bool {
+	if t.CVM == nil || !t.CVM.Method.IsPIN() {
+		return false
+	}
+	return t.CVM.Result == CVMResultSuccessful || t.CVM.Result == CVMResultUnknown
+}
+
 func (t *Tlv) ValidatePan() bool {
 	sum := 0
 	alt := false
@@ -113,67 +366,16 @@ func (t *Tlv) ValidatePan() bool {
 	return sum%10 == 0
 }
 
-var bit01Values = map[string]struct{}{
-	"00": {},
-	"01": {},
-	"02": {},
-	"03": {},
-	"04": {},
-	"05": {},
-	"06": {},
-	"07": {},
-	"1D": {},
-	"1E": {},
-	"1F": {},
-	"20": {},
-	"FF": {},
-}
-
-var bit02Values = map[string]struct{}{
-	"00": {},
-	"01": {},
-	"02": {},
-	"03": {},
-	"04": {},
-	"05": {},
-	"06": {},
-	"07": {},
-	"08": {},
-	"09": {},
-	"FF": {},
-}
-
-var bit03Values = map[string]struct{}{
-	"00": {},
-	"01": {},
-	"02": {},
-	"03": {},
-	"04": {},
-	"05": {},
-	"FF": {},
-}
-
 func (t *Tlv) ValidateCVM() error {
-	if len(t.CVM) < 6 {
-		return fmt.Errorf("CVM must be at least 6 characters, got %d", len(t.CVM))
-	}
-
-	bit01 := t.CVM[:2]
-	bit02 := t.CVM[2:4]
-	bit03 := t.CVM[4:6]
-
-	if _, exists := bit01Values[bit01]; !exists {
-		return fmt.Errorf("invalid CVM bit 1 value '%s': not a supported method", bit01)
+	if t.CVM == nil {
+		return fmt.Errorf("field CVM is required")
 	}
-
-	if _, exists := bit02Values[bit02]; !exists {
-		return fmt.Errorf("invalid CVM bit 2 value '%s': not a supported condition", bit02)
+	if err := t.CVM.Validate(); err != nil {
+		return err
 	}
-
-	if _, exists := bit03Values[bit03]; !exists {
-		return fmt.Errorf("invalid CVM bit 3 value '%s': not a supported value", bit03)
+	if t.CVM.Result == CVMResultFailed {
+		t.TVR.Set(tlv.TVRCVMUnsuccessful)
 	}
-
 	return nil
 }
 
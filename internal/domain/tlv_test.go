@@ -9,39 +9,198 @@ import (
 
 func TestTlv_Populate(t *testing.T) {
 	tests := []struct {
-		name    string
-		tlvs    []pkgtlv.TLV
-		want    Tlv
-		wantErr bool
+		name          string
+		tlvs          []pkgtlv.TLV
+		opts          []PopulateOption
+		want          Tlv
+		wantConflicts int
+		wantErr       bool
 	}{
 		{
 			name: "populate all fields successfully",
 			tlvs: []pkgtlv.TLV{
-				{Tag: hexToBytes("5A"), Value: hexToBytes("1234567890123456")},
-				{Tag: hexToBytes("5F24"), Value: hexToBytes("251231")},
-				{Tag: hexToBytes("9F34"), Value: hexToBytes("1F0000")},
+				{Tag: 0x5A, Value: hexToBytes("1234567890123456")},
+				{Tag: 0x5F24, Value: hexToBytes("251231")},
+				{Tag: 0x9F34, Value: hexToBytes("1F0000")},
 			},
 			want: Tlv{
 				Pan:          "1234567890123456",
-				DataValidade: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
-				CVM:          "1F0000",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          cvmResult("1F0000"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "populate from nested READ RECORD template",
+			tlvs: []pkgtlv.TLV{
+				{
+					Tag: 0x70,
+					Children: []pkgtlv.TLV{
+						{Tag: 0x5A, Value: hexToBytes("4539578763621486")},
+						{Tag: 0x5F24, Value: hexToBytes("251231")},
+					},
+				},
+				{Tag: 0x9F34, Value: hexToBytes("1F0000")},
+			},
+			want: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: false,
 		},
 		{
 			name: "populate with partial data",
 			tlvs: []pkgtlv.TLV{
-				{Tag: hexToBytes("5A"), Value: hexToBytes("9876543210987654")},
+				{Tag: 0x5A, Value: hexToBytes("9876543210987654")},
 			},
 			want: Tlv{
 				Pan: "9876543210987654",
 			},
 			wantErr: false,
 		},
+		{
+			name: "populate PAN and expiry from track 2",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x57, Value: hexToBytes("4539578763621486D25122011234567F")},
+				{Tag: 0x9F34, Value: hexToBytes("1F0000")},
+			},
+			want: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          cvmResult("1F0000"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "populate with invalid track 2",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x57, Value: hexToBytes("45395787636214862512201F")},
+			},
+			want:    Tlv{},
+			wantErr: true,
+		},
+		{
+			name: "duplicate PAN reported and last occurrence wins by default",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x5A, Value: hexToBytes("4539578763621486")},
+				{Tag: 0x5A, Value: hexToBytes("4111111111111111")},
+			},
+			want: Tlv{
+				Pan: "4111111111111111",
+			},
+			wantConflicts: 1,
+			wantErr:       false,
+		},
+		{
+			name: "duplicate PAN with first-wins policy",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x5A, Value: hexToBytes("4539578763621486")},
+				{Tag: 0x5A, Value: hexToBytes("4111111111111111")},
+			},
+			opts: []PopulateOption{WithDuplicatePolicy(pkgtlv.DuplicateFirstWins)},
+			want: Tlv{
+				Pan: "4539578763621486",
+			},
+			wantConflicts: 1,
+			wantErr:       false,
+		},
+		{
+			name: "duplicate PAN rejected",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x5A, Value: hexToBytes("4539578763621486")},
+				{Tag: 0x5A, Value: hexToBytes("4111111111111111")},
+			},
+			opts:    []PopulateOption{WithDuplicatePolicy(pkgtlv.DuplicateReject)},
+			want:    Tlv{},
+			wantErr: true,
+		},
+		{
+			name: "duplicate PAN across records rejected",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x70, Children: []pkgtlv.TLV{{Tag: 0x5A, Value: hexToBytes("4539578763621486")}}},
+				{Tag: 0x70, Children: []pkgtlv.TLV{{Tag: 0x5A, Value: hexToBytes("4111111111111111")}}},
+			},
+			opts:    []PopulateOption{WithDuplicatePolicy(pkgtlv.DuplicateReject)},
+			want:    Tlv{},
+			wantErr: true,
+		},
+		{
+			name: "populate expiry and effective dates with century window",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x5F24, Value: hexToBytes("300630")},
+				{Tag: 0x5F25, Value: hexToBytes("250101")},
+			},
+			opts: []PopulateOption{WithCenturyPivot(20)},
+			want: Tlv{
+				DataValidade: time.Date(1930, 6, 30, 0, 0, 0, 0, time.UTC),
+				DataEfetiva:  time.Date(1925, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: false,
+		},
+		{
+			name: "populate track 2 expiry with century window",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x57, Value: hexToBytes("4539578763621486D30062011234567F")},
+			},
+			opts: []PopulateOption{WithCenturyPivot(20)},
+			want: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(1930, 6, 30, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: false,
+		},
+		{
+			name: "populate with invalid expiry day",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x5F24, Value: hexToBytes("250231")},
+			},
+			want:    Tlv{},
+			wantErr: true,
+		},
+		{
+			name: "populate amounts, currency and country",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x9F02, Value: hexToBytes("000000001250")},
+				{Tag: 0x9F03, Value: hexToBytes("000000000200")},
+				{Tag: 0x5F2A, Value: hexToBytes("0986")},
+				{Tag: 0x5F36, Value: hexToBytes("02")},
+				{Tag: 0x9F1A, Value: hexToBytes("0076")},
+			},
+			want: Tlv{
+				AmountAuthorised:    1250,
+				AmountOther:         200,
+				TransactionCurrency: 986,
+				TerminalCountry:     76,
+			},
+			wantErr: false,
+		},
+		{
+			name: "populate issuer action codes",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x9F0D, Value: hexToBytes("F040FC8000")},
+				{Tag: 0x9F0E, Value: hexToBytes("0010000000")},
+				{Tag: 0x9F0F, Value: hexToBytes("F068FC9800")},
+			},
+			want: Tlv{
+				IACDefault: &ActionCode{0xF0, 0x40, 0xFC, 0x80, 0x00},
+				IACDenial:  &ActionCode{0x00, 0x10, 0x00, 0x00, 0x00},
+				IACOnline:  &ActionCode{0xF0, 0x68, 0xFC, 0x98, 0x00},
+			},
+			wantErr: false,
+		},
+		{
+			name: "populate with invalid issuer action code length",
+			tlvs: []pkgtlv.TLV{
+				{Tag: 0x9F0E, Value: hexToBytes("0010")},
+			},
+			want:    Tlv{},
+			wantErr: true,
+		},
 		{
 			name: "populate with invalid date format",
 			tlvs: []pkgtlv.TLV{
-				{Tag: hexToBytes("5F24"), Value: hexToBytes("9999")},
+				{Tag: 0x5F24, Value: hexToBytes("9999")},
 			},
 			want:    Tlv{},
 			wantErr: true,
@@ -51,7 +210,7 @@ func TestTlv_Populate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tlv := &Tlv{}
-			err := tlv.Populate(tt.tlvs)
+			err := tlv.Populate(tt.tlvs, tt.opts...)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Tlv.Populate() error = %v, wantErr %v", err, tt.wantErr)
@@ -65,14 +224,35 @@ func TestTlv_Populate(t *testing.T) {
 				if !tt.want.DataValidade.IsZero() && !tlv.DataValidade.Equal(tt.want.DataValidade) {
 					t.Errorf("Tlv.Populate() DataValidade = %v, want %v", tlv.DataValidade, tt.want.DataValidade)
 				}
-				if tlv.CVM != tt.want.CVM {
+				if !tt.want.DataEfetiva.IsZero() && !tlv.DataEfetiva.Equal(tt.want.DataEfetiva) {
+					t.Errorf("Tlv.Populate() DataEfetiva = %v, want %v", tlv.DataEfetiva, tt.want.DataEfetiva)
+				}
+				if (tlv.CVM == nil) != (tt.want.CVM == nil) || (tlv.CVM != nil && *tlv.CVM != *tt.want.CVM) {
 					t.Errorf("Tlv.Populate() CVM = %v, want %v", tlv.CVM, tt.want.CVM)
 				}
+				if tlv.AmountAuthorised != tt.want.AmountAuthorised || tlv.AmountOther != tt.want.AmountOther {
+					t.Errorf("Tlv.Populate() amounts = %d/%d, want %d/%d", tlv.AmountAuthorised, tlv.AmountOther, tt.want.AmountAuthorised, tt.want.AmountOther)
+				}
+				if tlv.TransactionCurrency != tt.want.TransactionCurrency {
+					t.Errorf("Tlv.Populate() TransactionCurrency = %v, want %v", tlv.TransactionCurrency, tt.want.TransactionCurrency)
+				}
+				if tlv.TerminalCountry != tt.want.TerminalCountry {
+					t.Errorf("Tlv.Populate() TerminalCountry = %v, want %v", tlv.TerminalCountry, tt.want.TerminalCountry)
+				}
+				if tlv.IssuerActionCodes() != tt.want.IssuerActionCodes() {
+					t.Errorf("Tlv.Populate() IssuerActionCodes = %v, want %v", tlv.IssuerActionCodes(), tt.want.IssuerActionCodes())
+				}
+				if len(tlv.Conflicts) != tt.wantConflicts {
+					t.Errorf("Tlv.Populate() Conflicts = %v, want %d", tlv.Conflicts, tt.wantConflicts)
+				}
 			}
 		})
 	}
 }
 
+// now is the instant at which Validate tests run.
+var now = time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+
 func TestTlv_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -83,8 +263,8 @@ func TestTlv_Validate(t *testing.T) {
 			name: "valid Tlv with all fields",
 			tlv: Tlv{
 				Pan:          "4539578763621486",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "1F0000",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: false,
 		},
@@ -92,8 +272,8 @@ func TestTlv_Validate(t *testing.T) {
 			name: "invalid Tlv - Pan too short",
 			tlv: Tlv{
 				Pan:          "123456789012",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "1F0000",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: true,
 		},
@@ -101,8 +281,8 @@ func TestTlv_Validate(t *testing.T) {
 			name: "invalid Tlv - Pan too long",
 			tlv: Tlv{
 				Pan:          "12345678901234567890",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "1F0000",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: true,
 		},
@@ -110,8 +290,8 @@ func TestTlv_Validate(t *testing.T) {
 			name: "invalid Tlv - missing Pan",
 			tlv: Tlv{
 				Pan:          "",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "1F0000",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: true,
 		},
@@ -119,8 +299,16 @@ func TestTlv_Validate(t *testing.T) {
 			name: "invalid Tlv - missing CVM",
 			tlv: Tlv{
 				Pan:          "1234567890123456",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "",
+				DataValidade: now.AddDate(1, 0, 0),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Tlv - Pan length not valid for brand",
+			tlv: Tlv{
+				Pan:          "3782822463100050",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: true,
 		},
@@ -128,8 +316,8 @@ func TestTlv_Validate(t *testing.T) {
 			name: "valid Tlv - Pan with 14 digits",
 			tlv: Tlv{
 				Pan:          "4539578763621486",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "1F0000",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: false,
 		},
@@ -137,8 +325,8 @@ func TestTlv_Validate(t *testing.T) {
 			name: "valid Tlv - Pan with 18 digits",
 			tlv: Tlv{
 				Pan:          "4539578763621486",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "1F0000",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: false,
 		},
@@ -146,8 +334,179 @@ func TestTlv_Validate(t *testing.T) {
 			name: "invalid Tlv - Pan is not valid",
 			tlv: Tlv{
 				Pan:          "4539578763621487",
-				DataValidade: time.Now().AddDate(1, 0, 0),
-				CVM:          "1F0000",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid Tlv - track 2 matches PAN and expiry",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(now.Year()+1, 12, 1, 0, 0, 0, 0, time.UTC),
+				CVM:          cvmResult("1F0000"),
+				Track2: &Track2{
+					Pan:          "4539578763621486",
+					DataValidade: time.Date(now.Year()+1, 12, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid Tlv - track 2 PAN differs",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(now.Year()+1, 12, 1, 0, 0, 0, 0, time.UTC),
+				CVM:          cvmResult("1F0000"),
+				Track2: &Track2{
+					Pan:          "4111111111111111",
+					DataValidade: time.Date(now.Year()+1, 12, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Tlv - track 2 expiry differs",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(now.Year()+1, 12, 1, 0, 0, 0, 0, time.UTC),
+				CVM:          cvmResult("1F0000"),
+				Track2: &Track2{
+					Pan:          "4539578763621486",
+					DataValidade: time.Date(now.Year()+1, 11, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Tlv - ATM only service code",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("010002"),
+				ServiceCode:  &ServiceCode{Interchange: InterchangeInternational, Services: ServicesATMOnlyPINRequired},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Tlv - service code requires PIN but no CVM performed",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("1F0000"),
+				ServiceCode:  &ServiceCode{Interchange: InterchangeInternationalIC, Services: ServicesNoRestrictionsPINRequired},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Tlv - service code requires PIN but offline PIN failed",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("010001"),
+				ServiceCode:  &ServiceCode{Interchange: InterchangeInternationalIC, Services: ServicesNoRestrictionsPINRequired},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid Tlv - service code requires PIN and offline PIN verified",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("010002"),
+				ServiceCode:  &ServiceCode{Interchange: InterchangeInternationalIC, Services: ServicesNoRestrictionsPINRequired},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid Tlv - service code requires PIN and online PIN performed",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("020000"),
+				ServiceCode:  &ServiceCode{Interchange: InterchangeInternationalIC, Services: ServicesNoRestrictionsPINRequired},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid Tlv - card expires today",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: today(0),
+				CVM:          cvmResult("1F0000"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid Tlv - card expired yesterday",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: today(-1),
+				CVM:          cvmResult("1F0000"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid Tlv - card effective today",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				DataEfetiva:  today(0),
+				CVM:          cvmResult("1F0000"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid Tlv - card not yet effective",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				DataEfetiva:  today(1),
+				CVM:          cvmResult("1F0000"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid Tlv - amount with currency",
+			tlv: Tlv{
+				Pan:                 "4539578763621486",
+				DataValidade:        now.AddDate(1, 0, 0),
+				CVM:                 cvmResult("1F0000"),
+				AmountAuthorised:    1250,
+				TransactionCurrency: 986,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid Tlv - amount without currency",
+			tlv: Tlv{
+				Pan:              "4539578763621486",
+				DataValidade:     now.AddDate(1, 0, 0),
+				CVM:              cvmResult("1F0000"),
+				AmountAuthorised: 1250,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Tlv - unknown currency code",
+			tlv: Tlv{
+				Pan:                 "4539578763621486",
+				DataValidade:        now.AddDate(1, 0, 0),
+				CVM:                 cvmResult("1F0000"),
+				AmountAuthorised:    1250,
+				TransactionCurrency: 999,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Tlv - amount other above amount authorised",
+			tlv: Tlv{
+				Pan:                 "4539578763621486",
+				DataValidade:        now.AddDate(1, 0, 0),
+				CVM:                 cvmResult("1F0000"),
+				AmountAuthorised:    1000,
+				AmountOther:         2000,
+				TransactionCurrency: 986,
 			},
 			wantErr: true,
 		},
@@ -155,8 +514,8 @@ func TestTlv_Validate(t *testing.T) {
 			name: "invalid Tlv - Date validade is not valid",
 			tlv: Tlv{
 				Pan:          "4539578763621487",
-				DataValidade: time.Now(),
-				CVM:          "1F0000",
+				DataValidade: now,
+				CVM:          cvmResult("1F0000"),
 			},
 			wantErr: true,
 		},
@@ -164,7 +523,7 @@ func TestTlv_Validate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.tlv.Validate()
+			err := tt.tlv.Validate(now)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Tlv.Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -172,6 +531,55 @@ func TestTlv_Validate(t *testing.T) {
 	}
 }
 
+func TestTlv_Validate_TimeZone(t *testing.T) {
+	card := Tlv{
+		Pan:          "4539578763621486",
+		DataValidade: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+		CVM:          cvmResult("1F0000"),
+	}
+	instant := time.Date(2025, 6, 16, 1, 0, 0, 0, time.UTC)
+
+	if err := card.Validate(instant); err == nil {
+		t.Errorf("Tlv.Validate() in UTC expected card expired error")
+	}
+
+	brt := time.FixedZone("BRT", -3*60*60)
+	if err := card.Validate(instant.In(brt)); err != nil {
+		t.Errorf("Tlv.Validate() in BRT unexpected error = %v", err)
+	}
+}
+
+func TestTlv_Amount(t *testing.T) {
+	exponent := uint8(0)
+	tests := []struct {
+		name string
+		tlv  Tlv
+		want string
+	}{
+		{name: "exponent from currency table", tlv: Tlv{AmountAuthorised: 1250, TransactionCurrency: 392}, want: "JPY 1250"},
+		{name: "exponent from 5F36", tlv: Tlv{AmountAuthorised: 1250, TransactionCurrency: 986, CurrencyExponent: &exponent}, want: "BRL 1250"},
+		{name: "default exponent for unknown currency", tlv: Tlv{AmountAuthorised: 1250, TransactionCurrency: 1}, want: "001 12.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tlv.Amount().String(); got != tt.want {
+				t.Errorf("Tlv.Amount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// today returns the date of now shifted by days.
+func today(days int) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, time.UTC)
+}
+
+func cvmResult(s string) *CVMResult {
+	r, _ := ParseCVMResult(hexToBytes(s))
+	return &r
+}
+
 func hexToBytes(s string) []byte {
 	b, _ := hex.DecodeString(s)
 	return b
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/josuesantos1/emv/pkg/tlv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const track2Separator = 'D'
+
+// Track2 is the decoded Track 2 Equivalent Data (tag 57): PAN, separator
+// 'D', expiry YYMM, service code, discretionary data and an optional 'F'
+// pad nibble. Track 2 carries no expiry day, so DataValidade is set to
+// the last day of the expiry month.
+type Track2 struct {
+	Pan           string
+	DataValidade  time.Time
+	ServiceCode   string
+	Discretionary string
+}
+
+// ParseTrack2 decodes the value of tag 57, expanding the two-digit expiry
+// year with the century window of pivot (see tlv.ExpandYear).
+func ParseTrack2(value []byte, pivot int) (Track2, error) {
+	digits := strings.ToUpper(fmt.Sprintf("%X", value))
+	digits = strings.TrimSuffix(digits, "F")
+
+	pan, rest, found := strings.Cut(digits, string(track2Separator))
+	if !found {
+		return Track2{}, fmt.Errorf("invalid track 2: field separator 'D' not found")
+	}
+
+	if pan == "" || !isDigits(pan) {
+		return Track2{}, fmt.Errorf("invalid track 2: PAN '%s' is not numeric", pan)
+	}
+
+	if len(rest) < 7 {
+		return Track2{}, fmt.Errorf("invalid track 2: expected expiry date and service code after separator")
+	}
+
+	if !isDigits(rest) {
+		return Track2{}, fmt.Errorf("invalid track 2: data after separator '%s' is not numeric", rest)
+	}
+
+	yy, _ := strconv.Atoi(rest[0:2])
+	expiry, err := time.Parse("2006-01", fmt.Sprintf("%d-%s", tlv.ExpandYear(yy, pivot), rest[2:4]))
+	if err != nil {
+		return Track2{}, fmt.Errorf("invalid track 2 expiry date '%s': %w", rest[0:4], err)
+	}
+
+	return Track2{
+		Pan:           pan,
+		DataValidade:  expiry.AddDate(0, 1, -1),
+		ServiceCode:   rest[4:7],
+		Discretionary: rest[7:],
+	}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
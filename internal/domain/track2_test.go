@@ -0,0 +1,94 @@
+package domain
+
+import (
+	pkgtlv "github.com/josuesantos1/emv/pkg/tlv"
+	"testing"
+	"time"
+)
+
+func TestParseTrack2(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Track2
+		wantErr bool
+	}{
+		{
+			name:  "track 2 with padding",
+			input: "4539578763621486D25122011234567F",
+			want: Track2{
+				Pan:           "4539578763621486",
+				DataValidade:  time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				ServiceCode:   "201",
+				Discretionary: "1234567",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "track 2 without discretionary data",
+			input: "4539578763621486D2512101",
+			want: Track2{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				ServiceCode:  "101",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "expiry in February of a leap year",
+			input: "4539578763621486D2802201",
+			want: Track2{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+				ServiceCode:  "201",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing separator",
+			input:   "45395787636214862512201F",
+			wantErr: true,
+		},
+		{
+			name:    "missing service code",
+			input:   "4539578763621486D2512F",
+			wantErr: true,
+		},
+		{
+			name:    "invalid month",
+			input:   "4539578763621486D2513201",
+			wantErr: true,
+		},
+		{
+			name:    "non numeric PAN",
+			input:   "4539A78763621486D2512201",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrack2(hexToBytes(tt.input), pkgtlv.DefaultCenturyPivot)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTrack2() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil {
+				if got.Pan != tt.want.Pan {
+					t.Errorf("ParseTrack2() Pan = %v, want %v", got.Pan, tt.want.Pan)
+				}
+				if !got.DataValidade.Equal(tt.want.DataValidade) {
+					t.Errorf("ParseTrack2() DataValidade = %v, want %v", got.DataValidade, tt.want.DataValidade)
+				}
+				if got.ServiceCode != tt.want.ServiceCode {
+					t.Errorf("ParseTrack2() ServiceCode = %v, want %v", got.ServiceCode, tt.want.ServiceCode)
+				}
+				if got.Discretionary != tt.want.Discretionary {
+					t.Errorf("ParseTrack2() Discretionary = %v, want %v", got.Discretionary, tt.want.Discretionary)
+				}
+			}
+		})
+	}
+}
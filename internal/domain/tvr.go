@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/josuesantos1/emv/pkg/tlv"
+)
+
+// TVR is the Terminal Verification Results (tag 95): the outcome of the
+// checks the terminal ran on the transaction. Bits are named in the tlv
+// package, e.g. tlv.TVRExpiredApplication.
+type TVR [5]byte
+
+// TSI is the Transaction Status Information (tag 9B): the functions the
+// terminal performed, e.g. tlv.TSICardholderVerificationPerformed.
+type TSI [2]byte
+
+func (t *TVR) Set(bit tlv.Bit) {
+	setBit(t[:], bit)
+}
+
+func (t TVR) IsSet(bit tlv.Bit) bool {
+	return bit.IsSet(t[:])
+}
+
+// Merge sets every bit that is set in other.
+func (t *TVR) Merge(other TVR) {
+	for i, b := range other {
+		t[i] |= b
+	}
+}
+
+// Names lists the names of the bits set, in EMV order.
+func (t TVR) Names() []string {
+	return bitNames(t[:], tlv.TVRBits)
+}
+
+func (t TVR) Hex() string {
+	return fmt.Sprintf("%X", t[:])
+}
+
+func (t TVR) String() string {
+	return t.Hex()
+}
+
+func (t *TSI) Set(bit tlv.Bit) {
+	setBit(t[:], bit)
+}
+
+func (t TSI) IsSet(bit tlv.Bit) bool {
+	return bit.IsSet(t[:])
+}
+
+// Names lists the names of the bits set, in EMV order.
+func (t TSI) Names() []string {
+	return bitNames(t[:], tlv.TSIBits)
+}
+
+func (t TSI) Hex() string {
+	return fmt.Sprintf("%X", t[:])
+}
+
+func (t TSI) String() string {
+	return t.Hex()
+}
+
+func setBit(value []byte, bit tlv.Bit) {
+	if bit.Byte >= 1 && bit.Byte <= len(value) {
+		value[bit.Byte-1] |= bit.Mask
+	}
+}
+
+func bitNames(value []byte, bits []tlv.Bit) []string {
+	var names []string
+	for _, bit := range bits {
+		if bit.IsSet(value) {
+			names = append(names, bit.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/josuesantos1/emv/pkg/tlv"
+)
+
+func TestTVR(t *testing.T) {
+	var tvr TVR
+	tvr.Set(tlv.TVROfflineDataAuthenticationNotPerformed)
+	tvr.Set(tlv.TVRExpiredApplication)
+	tvr.Merge(TVR{0, 0, 0, 0x80, 0})
+
+	if got := tvr.Hex(); got != "8040008000" {
+		t.Errorf("TVR.Hex() = %v, want 8040008000", got)
+	}
+	if !tvr.IsSet(tlv.TVRFloorLimitExceeded) {
+		t.Errorf("TVR.IsSet(floor limit) = false, want true")
+	}
+	if tvr.IsSet(tlv.TVRNewCard) {
+		t.Errorf("TVR.IsSet(new card) = true, want false")
+	}
+
+	want := []string{
+		"Offline data authentication was not performed",
+		"Expired application",
+		"Transaction exceeds floor limit",
+	}
+	if got := tvr.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TVR.Names() = %v, want %v", got, want)
+	}
+}
+
+func TestTSI(t *testing.T) {
+	var tsi TSI
+	tsi.Set(tlv.TSICardholderVerificationPerformed)
+	tsi.Set(tlv.TSITerminalRiskManagementPerformed)
+
+	if got := tsi.Hex(); got != "4800" {
+		t.Errorf("TSI.Hex() = %v, want 4800", got)
+	}
+
+	want := []string{"Cardholder verification was performed", "Terminal risk management was performed"}
+	if got := tsi.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TSI.Names() = %v, want %v", got, want)
+	}
+}
+
+func TestTlv_Validate_TVR(t *testing.T) {
+	tests := []struct {
+		name    string
+		tlv     Tlv
+		wantTVR string
+		wantErr bool
+		// malformed marks errors for invalid card data rather than a
+		// failed card check.
+		malformed bool
+	}{
+		{
+			name:    "valid card sets no bits",
+			tlv:     Tlv{Pan: "4539578763621486", DataValidade: now.AddDate(1, 0, 0), CVM: cvmResult("1F0002")},
+			wantTVR: "0000000000",
+		},
+		{
+			name:      "missing PAN terminates without TVR bit",
+			tlv:       Tlv{DataValidade: now.AddDate(1, 0, 0), CVM: cvmResult("1F0002")},
+			wantTVR:   "0000000000",
+			wantErr:   true,
+			malformed: true,
+		},
+		{
+			name:      "missing expiry terminates without TVR bit",
+			tlv:       Tlv{Pan: "4539578763621486", CVM: cvmResult("1F0002")},
+			wantTVR:   "0000000000",
+			wantErr:   true,
+			malformed: true,
+		},
+		{
+			name:    "expired application",
+			tlv:     Tlv{Pan: "4539578763621486", DataValidade: today(-1), CVM: cvmResult("1F0002")},
+			wantTVR: "0040000000",
+			wantErr: true,
+		},
+		{
+			name:    "application not yet effective",
+			tlv:     Tlv{Pan: "4539578763621486", DataValidade: now.AddDate(1, 0, 0), DataEfetiva: today(1), CVM: cvmResult("1F0002")},
+			wantTVR: "0020000000",
+			wantErr: true,
+		},
+		{
+			name:    "cardholder verification failed",
+			tlv:     Tlv{Pan: "4539578763621486", DataValidade: now.AddDate(1, 0, 0), CVM: cvmResult("3F0001")},
+			wantTVR: "0000800000",
+		},
+		{
+			name: "service not allowed",
+			tlv: Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: now.AddDate(1, 0, 0),
+				CVM:          cvmResult("010002"),
+				ServiceCode:  &ServiceCode{Interchange: InterchangeInternational, Services: ServicesATMOnlyPINRequired},
+			},
+			wantTVR: "0010000000",
+			wantErr: true,
+		},
+		{
+			name:      "malformed PAN sets no bit",
+			tlv:       Tlv{Pan: "4539578763621487", DataValidade: now.AddDate(1, 0, 0), CVM: cvmResult("1F0002")},
+			wantTVR:   "0000000000",
+			wantErr:   true,
+			malformed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tlv.Validate(now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Tlv.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tt.tlv.TVR.Hex(); got != tt.wantTVR {
+				t.Errorf("Tlv.Validate() TVR = %v, want %v", got, tt.wantTVR)
+			}
+			if err == nil {
+				return
+			}
+
+			var check *CheckError
+			if errors.As(err, &check) == tt.malformed {
+				t.Errorf("Tlv.Validate() error = %T, want CheckError %v", err, !tt.malformed)
+			}
+			if check != nil && !tt.tlv.TVR.IsSet(check.Bit) {
+				t.Errorf("Tlv.Validate() CheckError bit %v not set in TVR %v", check.Bit, tt.tlv.TVR)
+			}
+		})
+	}
+}
@@ -16,9 +16,16 @@ type HTTPGateway struct {
 }
 
 type authorizationRequest struct {
-	Pan          string    `json:"pan"`
-	DataValidade time.Time `json:"data_validade"`
-	CVM          string    `json:"cvm"`
+	Pan              string    `json:"pan"`
+	DataValidade     time.Time `json:"data_validade"`
+	CVM              string    `json:"cvm"`
+	Amount           uint64    `json:"amount"`
+	AmountOther      uint64    `json:"amount_other,omitempty"`
+	Currency         string    `json:"currency"`
+	CurrencyExponent int       `json:"currency_exponent"`
+	Country          string    `json:"country,omitempty"`
+	TVR              string    `json:"tvr"`
+	TSI              string    `json:"tsi"`
 }
 
 type authorizationResponse struct {
@@ -37,10 +44,20 @@ func NewHTTPGateway(baseURL string) *HTTPGateway {
 }
 
 func (g *HTTPGateway) Authorize(transaction *domain.Tlv) (bool, error) {
+	amount := transaction.Amount()
 	req := authorizationRequest{
-		Pan:          transaction.Pan,
-		DataValidade: transaction.DataValidade,
-		CVM:          transaction.CVM,
+		Pan:              transaction.Pan,
+		DataValidade:     transaction.DataValidade,
+		CVM:              transaction.CVM.Hex(),
+		Amount:           amount.Amount,
+		AmountOther:      transaction.AmountOther,
+		Currency:         amount.CurrencyCode(),
+		CurrencyExponent: amount.Exponent,
+		TVR:              transaction.TVR.Hex(),
+		TSI:              transaction.TSI.Hex(),
+	}
+	if transaction.TerminalCountry != 0 {
+		req.Country = fmt.Sprintf("%03d", transaction.TerminalCountry)
 	}
 
 	body, err := json.Marshal(req)
@@ -1,47 +1,280 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/josuesantos1/emv/internal/clock"
 	domain "github.com/josuesantos1/emv/internal/domain"
+	"github.com/josuesantos1/emv/internal/risk"
+	"github.com/josuesantos1/emv/pkg/tlv"
 )
 
 type TransactionResult struct {
 	Approved     bool
 	Message      string
 	Pan          string
+	Brand        domain.Brand
 	DataValidade time.Time
-	CVM          string
-	Timestamp    time.Time
+	CVM          domain.CVMResult
+	Amount       domain.Money
+	AmountOther  domain.Money
+	Country      uint16
+	TVR          domain.TVR
+	TSI          domain.TSI
+	// Cryptogram is the Application Cryptogram requested by terminal
+	// action analysis.
+	Cryptogram     domain.CryptogramType
+	ServiceCode    string
+	OnlineRequired bool
+	PINRequired    bool
+	Conflicts      []string
+	Timestamp      time.Time
 }
 
 type Gateway interface {
 	Authorize(transaction *domain.Tlv) (bool, error)
 }
 
-func ProcessTransaction(transaction *domain.Tlv, gateway Gateway) (*TransactionResult, error) {
-	if err := transaction.Validate(); err != nil {
-		return nil, err
+// Processor validates card data and submits it to a Gateway, reading the
+// transaction time from its clock.
+type Processor struct {
+	gateway  Gateway
+	clock    clock.Clock
+	terminal Terminal
+	risk     *risk.Manager
+}
+
+// Terminal describes the capabilities of the terminal the processor runs on.
+type Terminal struct {
+	// Capabilities is the Terminal Capabilities (9F33).
+	Capabilities [3]byte
+	Unattended   bool
+	// PINPad collects PINs; nil means the terminal has no PIN pad.
+	PINPad domain.PINPad
+	// ActionCodes are the Terminal Action Codes used in terminal action
+	// analysis.
+	ActionCodes domain.ActionCodes
+	// OfflineOnly means the terminal cannot send transactions online.
+	OfflineOnly bool
+}
+
+// DefaultTerminal is an attended, online capable terminal without PIN pad
+// that supports signature and no CVM. Its Terminal Action Codes send
+// transactions online whenever offline data authentication was not
+// performed, which is always the case on this terminal.
+var DefaultTerminal = Terminal{
+	Capabilities: [3]byte{0xE0, 0x28, 0xC8},
+	ActionCodes: domain.ActionCodes{
+		Denial:  domain.ActionCode{0x00, 0x10, 0x00, 0x00, 0x00},
+		Online:  domain.ActionCode{0xDC, 0x40, 0x04, 0xF8, 0x00},
+		Default: domain.ActionCode{0xDC, 0x40, 0x00, 0xA8, 0x00},
+	},
+}
+
+// ProcessorOption configures a Processor.
+type ProcessorOption func(*Processor)
+
+// WithClock sets the clock used for validation and timestamps. The
+// default is the system clock in the local time zone.
+func WithClock(c clock.Clock) ProcessorOption {
+	return func(p *Processor) {
+		p.clock = c
 	}
+}
+
+// WithTerminal sets the terminal used for cardholder verification. The
+// default is DefaultTerminal.
+func WithTerminal(t Terminal) ProcessorOption {
+	return func(p *Processor) {
+		p.terminal = t
+	}
+}
 
-	authorized, err := gateway.Authorize(transaction)
+// WithRiskManager sets the terminal risk management run between
+// validation and terminal action analysis. Cards found in its exception
+// file, such as a hotlist.Store, are declined offline without going to the
+// gateway. The default has a zero floor limit, so every transaction
+// exceeds it, and no exception file.
+func WithRiskManager(m *risk.Manager) ProcessorOption {
+	return func(p *Processor) {
+		p.risk = m
+	}
+}
+
+func NewProcessor(gateway Gateway, opts ...ProcessorOption) *Processor {
+	p := &Processor{
+		gateway:  gateway,
+		clock:    clock.System{},
+		terminal: DefaultTerminal,
+		risk:     risk.NewManager(risk.Config{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// ProcessTransaction processes transaction with a Processor configured by
+// opts, using the system clock unless WithClock is given.
+func ProcessTransaction(transaction *domain.Tlv, gateway Gateway, opts ...ProcessorOption) (*TransactionResult, error) {
+	return NewProcessor(gateway, opts...).Process(transaction)
+}
+
+func (p *Processor) Process(transaction *domain.Tlv) (*TransactionResult, error) {
+	now := p.clock.Now()
+
+	// Offline data authentication is not supported by this terminal.
+	transaction.TVR.Set(tlv.TVROfflineDataAuthenticationNotPerformed)
+
+	approved, message, err := p.decide(transaction, now)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &TransactionResult{
-		Approved:     authorized,
+		Approved:     approved,
+		Message:      message,
 		Pan:          transaction.Pan,
+		Brand:        transaction.Brand(),
 		DataValidade: transaction.DataValidade,
-		CVM:          transaction.CVM,
-		Timestamp:    time.Now(),
+		Amount:       transaction.Amount(),
+		AmountOther:  transaction.OtherAmount(),
+		Country:      transaction.TerminalCountry,
+		TVR:          transaction.TVR,
+		TSI:          transaction.TSI,
+		Cryptogram:   transaction.Cryptogram,
+		Timestamp:    now,
 	}
 
-	if authorized {
-		result.Message = "Transaction authorized successfully"
-	} else {
-		result.Message = "Transaction rejected by gateway"
+	if transaction.CVM != nil {
+		result.CVM = *transaction.CVM
+	}
+
+	if sc := transaction.ServiceCode; sc != nil {
+		result.ServiceCode = sc.String()
+		result.OnlineRequired = sc.OnlineRequired()
+		result.PINRequired = sc.PINRequired()
+	}
+
+	for _, conflict := range transaction.Conflicts {
+		result.Conflicts = append(result.Conflicts, conflict.String())
 	}
 
 	return result, nil
 }
+
+// decide declines cards failing a card check, runs terminal risk
+// management, declines cards in the exception file and leaves every other card to
+// terminal action analysis. It only returns an error for malformed card
+// data or missing mandatory data.
+func (p *Processor) decide(transaction *domain.Tlv, now time.Time) (bool, string, error) {
+	if err := p.checkCard(transaction, now); err != nil {
+		var check *domain.CheckError
+		if !errors.As(err, &check) {
+			return false, "", err
+		}
+		transaction.Cryptogram = domain.CryptogramAAC
+		return false, "Transaction declined offline: " + check.Reason, nil
+	}
+
+	p.risk.Check(transaction, now)
+
+	if transaction.TVR.IsSet(tlv.TVRExceptionFile) {
+		transaction.Cryptogram = domain.CryptogramAAC
+		return false, "Transaction declined offline: card in exception file", nil
+	}
+
+	approved, message := p.analyzeActions(transaction)
+	return approved, message, nil
+}
+
+// checkCard runs the card checks before cardholder verification, so the
+// cardholder is never asked for a PIN on a card the terminal rejects, then
+// the checks depending on the verification outcome.
+func (p *Processor) checkCard(transaction *domain.Tlv, now time.Time) error {
+	if err := transaction.ValidateCard(now); err != nil {
+		return err
+	}
+
+	if transaction.CVMList != nil {
+		p.verifyCardholder(transaction)
+	}
+
+	return transaction.ValidateCardholder()
+}
+
+// analyzeActions performs terminal action analysis, records the requested
+// cryptogram on the transaction and submits it to the gateway when it has
+// to go online. When the gateway cannot be reached the Default action codes
+// decide the transaction offline, replacing the ARQC with a TC or an AAC. A
+// service code requiring online authorization never lets the transaction
+// be approved offline.
+func (p *Processor) analyzeActions(transaction *domain.Tlv) (bool, string) {
+	terminal := p.terminal.ActionCodes
+	issuer := transaction.IssuerActionCodes()
+	onlineRequired := transaction.ServiceCode != nil && transaction.ServiceCode.OnlineRequired()
+
+	transaction.Cryptogram = domain.AnalyzeActions(transaction.TVR, terminal, issuer, !p.terminal.OfflineOnly)
+
+	if onlineRequired && transaction.Cryptogram == domain.CryptogramTC {
+		if p.terminal.OfflineOnly {
+			transaction.Cryptogram = domain.CryptogramAAC
+			return false, "Transaction declined offline: service code requires online authorization"
+		}
+		transaction.Cryptogram = domain.CryptogramARQC
+	}
+
+	switch transaction.Cryptogram {
+	case domain.CryptogramAAC:
+		return false, "Transaction declined offline"
+	case domain.CryptogramTC:
+		return true, "Transaction approved offline"
+	}
+
+	authorized, err := p.gateway.Authorize(transaction)
+	if err != nil {
+		transaction.Cryptogram = domain.CryptogramAAC
+		if !onlineRequired {
+			transaction.Cryptogram = domain.DefaultAction(transaction.TVR, terminal, issuer)
+		}
+		if transaction.Cryptogram == domain.CryptogramTC {
+			return true, fmt.Sprintf("Transaction approved offline: unable to go online: %v", err)
+		}
+		return false, fmt.Sprintf("Transaction declined offline: unable to go online: %v", err)
+	}
+
+	if authorized {
+		return true, "Transaction authorized successfully"
+	}
+	return false, "Transaction rejected by gateway"
+}
+
+// verifyCardholder runs CVM List processing and records the resulting CVM
+// Results and TVR bits on the transaction.
+func (p *Processor) verifyCardholder(transaction *domain.Tlv) {
+	transactionType := domain.TransactionPurchase
+	if transaction.AmountOther > 0 {
+		transactionType = domain.TransactionPurchaseWithCashback
+	}
+
+	outcome := transaction.CVMList.Process(domain.CVMContext{
+		Amount:              transaction.AmountAuthorised,
+		Currency:            transaction.TransactionCurrency,
+		ApplicationCurrency: transaction.ApplicationCurrency,
+		Type:                transactionType,
+		Unattended:          p.terminal.Unattended,
+		Capabilities:        p.terminal.Capabilities,
+		PINPad:              p.terminal.PINPad,
+	})
+
+	transaction.CVM = &outcome.Results
+	transaction.TVR.Merge(outcome.TVR)
+
+	// A CVM List without rules means cardholder verification was not
+	// performed: CVM Results 3F0000 and no TSI bit.
+	if len(transaction.CVMList.Rules) > 0 {
+		transaction.TSI.Set(tlv.TSICardholderVerificationPerformed)
+	}
+}
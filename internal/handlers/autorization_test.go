@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/josuesantos1/emv/internal/clock"
+	domain "github.com/josuesantos1/emv/internal/domain"
+	"github.com/josuesantos1/emv/internal/hotlist"
+	"github.com/josuesantos1/emv/internal/risk"
+	"github.com/josuesantos1/emv/pkg/tlv"
+)
+
+type stubGateway struct {
+	approved bool
+	err      error
+	calls    int
+}
+
+func (g *stubGateway) Authorize(transaction *domain.Tlv) (bool, error) {
+	g.calls++
+	return g.approved, g.err
+}
+
+type stubExceptionFile map[string]bool
+
+func (f stubExceptionFile) Contains(pan string, sequence *uint8, at time.Time) bool {
+	return f[pan]
+}
+
+var noCVM = &domain.CVMResult{Method: domain.CVMNoCVMRequired, FailIfUnsuccessful: true, Result: domain.CVMResultSuccessful}
+
+func TestProcessor_Process(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		transaction  domain.Tlv
+		terminal     *Terminal
+		risk         *risk.Manager
+		hotlist      []hotlist.Entry
+		approved     bool
+		gatewayErr   error
+		wantApproved bool
+		wantMessage  string
+		wantCVM      string
+		wantTSI      string
+		wantCrypto   domain.CryptogramType
+		wantTVR      []tlv.Bit
+		wantCalls    int
+		wantErr      bool
+	}{
+		{
+			name: "approved by gateway",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			approved:     true,
+			wantApproved: true,
+			wantMessage:  "Transaction authorized successfully",
+			wantCrypto:   domain.CryptogramARQC,
+			wantCalls:    1,
+		},
+		{
+			name: "rejected by gateway",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			approved:    false,
+			wantMessage: "Transaction rejected by gateway",
+			wantCrypto:  domain.CryptogramARQC,
+			wantCalls:   1,
+		},
+		{
+			name: "CVM results computed from the CVM list",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVMList: &domain.CVMList{Rules: []domain.CVMRule{
+					{Method: domain.CVMOnlinePIN, ApplyNext: true, Condition: domain.CVMAlways},
+					{Method: domain.CVMSignature, Condition: domain.CVMAlways},
+				}},
+			},
+			approved:     true,
+			wantApproved: true,
+			wantMessage:  "Transaction authorized successfully",
+			wantCVM:      "1E0000",
+			wantTSI:      "4800",
+			wantCrypto:   domain.CryptogramARQC,
+			wantCalls:    1,
+		},
+		{
+			name: "CVM list without rules does not perform cardholder verification",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVMList:      &domain.CVMList{},
+			},
+			approved:     true,
+			wantApproved: true,
+			wantMessage:  "Transaction authorized successfully",
+			wantCVM:      "3F0000",
+			wantTSI:      "0800",
+			wantCrypto:   domain.CryptogramARQC,
+			wantCalls:    1,
+		},
+		{
+			name: "no CVM below amount X in application currency",
+			transaction: domain.Tlv{
+				Pan:                 "4539578763621486",
+				DataValidade:        time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				AmountAuthorised:    1250,
+				TransactionCurrency: 986,
+				ApplicationCurrency: 986,
+				CVMList: &domain.CVMList{AmountX: 5000, Rules: []domain.CVMRule{
+					{Method: domain.CVMNoCVMRequired, ApplyNext: true, Condition: domain.CVMIfUnderX},
+					{Method: domain.CVMSignature, Condition: domain.CVMAlways},
+				}},
+			},
+			approved:     true,
+			wantApproved: true,
+			wantMessage:  "Transaction authorized successfully",
+			wantCVM:      "5F0602",
+			wantCrypto:   domain.CryptogramARQC,
+			wantCalls:    1,
+		},
+		{
+			name: "approved offline when no action code matches",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+				IACOnline:    &domain.ActionCodeNone,
+				IACDefault:   &domain.ActionCodeNone,
+			},
+			terminal:     &Terminal{Capabilities: DefaultTerminal.Capabilities},
+			wantApproved: true,
+			wantMessage:  "Transaction approved offline",
+			wantCrypto:   domain.CryptogramTC,
+			wantCalls:    0,
+		},
+		{
+			name: "declined offline by issuer denial code",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+				IACDenial:    &domain.ActionCode{0x80, 0x00, 0x00, 0x00, 0x00},
+			},
+			wantMessage: "Transaction declined offline",
+			wantCrypto:  domain.CryptogramAAC,
+			wantCalls:   0,
+		},
+		{
+			name: "unable to go online falls back to default codes",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			gatewayErr:  errors.New("connection refused"),
+			wantMessage: "Transaction declined offline: unable to go online: connection refused",
+			wantCrypto:  domain.CryptogramAAC,
+			wantCalls:   1,
+		},
+		{
+			name: "unable to go online approved without default match",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+				IACDefault:   &domain.ActionCodeNone,
+			},
+			terminal: &Terminal{
+				Capabilities: DefaultTerminal.Capabilities,
+				ActionCodes:  domain.ActionCodes{Online: domain.ActionCodeAll},
+			},
+			gatewayErr:   errors.New("connection refused"),
+			wantApproved: true,
+			wantMessage:  "Transaction approved offline: unable to go online: connection refused",
+			wantCrypto:   domain.CryptogramTC,
+			wantCalls:    1,
+		},
+		{
+			name: "service code requiring online authorization forces ARQC",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+				ServiceCode:  &domain.ServiceCode{Interchange: domain.InterchangeInternationalIC, Authorization: domain.AuthorizationByIssuer, Services: domain.ServicesNoRestrictions},
+				IACOnline:    &domain.ActionCodeNone,
+				IACDefault:   &domain.ActionCodeNone,
+			},
+			terminal:     &Terminal{Capabilities: DefaultTerminal.Capabilities},
+			approved:     true,
+			wantApproved: true,
+			wantMessage:  "Transaction authorized successfully",
+			wantCrypto:   domain.CryptogramARQC,
+			wantCalls:    1,
+		},
+		{
+			name: "service code requiring online authorization declined when unable to go online",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+				ServiceCode:  &domain.ServiceCode{Interchange: domain.InterchangeInternationalIC, Authorization: domain.AuthorizationByIssuer, Services: domain.ServicesNoRestrictions},
+				IACOnline:    &domain.ActionCodeNone,
+				IACDefault:   &domain.ActionCodeNone,
+			},
+			terminal:    &Terminal{Capabilities: DefaultTerminal.Capabilities},
+			gatewayErr:  errors.New("connection refused"),
+			wantMessage: "Transaction declined offline: unable to go online: connection refused",
+			wantCrypto:  domain.CryptogramAAC,
+			wantCalls:   1,
+		},
+		{
+			name: "service code requiring online authorization declined by offline only terminal",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+				ServiceCode:  &domain.ServiceCode{Interchange: domain.InterchangeInternationalIC, Authorization: domain.AuthorizationByIssuer, Services: domain.ServicesNoRestrictions},
+				IACOnline:    &domain.ActionCodeNone,
+				IACDefault:   &domain.ActionCodeNone,
+			},
+			terminal:    &Terminal{Capabilities: DefaultTerminal.Capabilities, OfflineOnly: true},
+			wantMessage: "Transaction declined offline: service code requires online authorization",
+			wantCrypto:  domain.CryptogramAAC,
+			wantCalls:   0,
+		},
+		{
+			name: "offline only terminal applies default codes",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			terminal: &Terminal{
+				Capabilities: DefaultTerminal.Capabilities,
+				ActionCodes:  DefaultTerminal.ActionCodes,
+				OfflineOnly:  true,
+			},
+			wantMessage: "Transaction declined offline",
+			wantCrypto:  domain.CryptogramAAC,
+			wantCalls:   0,
+		},
+		{
+			name: "approved offline below floor limit",
+			transaction: domain.Tlv{
+				Pan:                 "4539578763621486",
+				DataValidade:        time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:                 noCVM,
+				AmountAuthorised:    1250,
+				TransactionCurrency: 986,
+				IACOnline:           &domain.ActionCode{0x00, 0x00, 0x00, 0x80, 0x00},
+				IACDefault:          &domain.ActionCodeNone,
+			},
+			terminal:     &Terminal{Capabilities: DefaultTerminal.Capabilities},
+			risk:         risk.NewManager(risk.Config{FloorLimit: 5000}),
+			wantApproved: true,
+			wantMessage:  "Transaction approved offline",
+			wantTSI:      "0800",
+			wantCrypto:   domain.CryptogramTC,
+			wantCalls:    0,
+		},
+		{
+			name: "sent online above floor limit",
+			transaction: domain.Tlv{
+				Pan:                 "4539578763621486",
+				DataValidade:        time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:                 noCVM,
+				AmountAuthorised:    7500,
+				TransactionCurrency: 986,
+				IACOnline:           &domain.ActionCode{0x00, 0x00, 0x00, 0x80, 0x00},
+				IACDefault:          &domain.ActionCodeNone,
+			},
+			terminal:     &Terminal{Capabilities: DefaultTerminal.Capabilities},
+			risk:         risk.NewManager(risk.Config{FloorLimit: 5000}),
+			approved:     true,
+			wantApproved: true,
+			wantMessage:  "Transaction authorized successfully",
+			wantCrypto:   domain.CryptogramARQC,
+			wantCalls:    1,
+		},
+		{
+			name: "card in exception file declined offline",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			risk:        risk.NewManager(risk.Config{}, risk.WithExceptionFile(stubExceptionFile{"4539578763621486": true})),
+			approved:    true,
+			wantMessage: "Transaction declined offline: card in exception file",
+			wantCrypto:  domain.CryptogramAAC,
+			wantTVR:     []tlv.Bit{tlv.TVRExceptionFile},
+			wantCalls:   0,
+		},
+		{
+			name: "card on hotlist declined offline",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			hotlist:     []hotlist.Entry{{PAN: "4539578763621486", Reason: "lost card"}},
+			approved:    true,
+			wantMessage: "Transaction declined offline: card in exception file",
+			wantCrypto:  domain.CryptogramAAC,
+			wantTVR:     []tlv.Bit{tlv.TVRExceptionFile},
+			wantCalls:   0,
+		},
+		{
+			name: "expired hotlist entry ignored",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			hotlist:      []hotlist.Entry{{PAN: "4539578763621486", Reason: "lost card", ExpiresAt: now}},
+			approved:     true,
+			wantApproved: true,
+			wantMessage:  "Transaction authorized successfully",
+			wantCrypto:   domain.CryptogramARQC,
+			wantCalls:    1,
+		},
+		{
+			name: "card expired at clock time is declined without going to gateway",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			approved:    true,
+			wantMessage: "Transaction declined offline: card expired: expiry date 2025-06-14 is before current date 2025-06-15",
+			wantCrypto:  domain.CryptogramAAC,
+			wantTVR:     []tlv.Bit{tlv.TVRExpiredApplication},
+			wantCalls:   0,
+		},
+		{
+			name: "service not allowed is declined with its TVR bit",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621486",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+				ServiceCode:  &domain.ServiceCode{Interchange: domain.InterchangeInternational, Services: domain.ServicesATMOnlyPINRequired},
+			},
+			approved:    true,
+			wantMessage: "Transaction declined offline: service code 103 does not allow purchases at a POS terminal",
+			wantCrypto:  domain.CryptogramAAC,
+			wantTVR:     []tlv.Bit{tlv.TVRServiceNotAllowed},
+			wantCalls:   0,
+		},
+		{
+			name: "missing PAN is an error",
+			transaction: domain.Tlv{
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			approved:  true,
+			wantCalls: 0,
+			wantErr:   true,
+		},
+		{
+			name: "missing expiry is an error",
+			transaction: domain.Tlv{
+				Pan: "4539578763621486",
+				CVM: noCVM,
+			},
+			approved:  true,
+			wantCalls: 0,
+			wantErr:   true,
+		},
+		{
+			name: "malformed PAN is an error",
+			transaction: domain.Tlv{
+				Pan:          "4539578763621487",
+				DataValidade: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				CVM:          noCVM,
+			},
+			approved:  true,
+			wantCalls: 0,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := &stubGateway{approved: tt.approved, err: tt.gatewayErr}
+			opts := []ProcessorOption{WithClock(clock.Fixed(now))}
+			if tt.terminal != nil {
+				opts = append(opts, WithTerminal(*tt.terminal))
+			}
+			if tt.risk != nil {
+				opts = append(opts, WithRiskManager(tt.risk))
+			}
+			if tt.hotlist != nil {
+				store := hotlist.NewStore()
+				for _, entry := range tt.hotlist {
+					if err := store.Add(entry); err != nil {
+						t.Fatal(err)
+					}
+				}
+				opts = append(opts, WithRiskManager(risk.NewManager(risk.Config{}, risk.WithExceptionFile(store))))
+			}
+			processor := NewProcessor(gw, opts...)
+
+			got, err := processor.Process(&tt.transaction)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Processor.Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gw.calls != tt.wantCalls {
+				t.Errorf("Processor.Process() gateway calls = %d, want %d", gw.calls, tt.wantCalls)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Approved != tt.wantApproved {
+				t.Errorf("Processor.Process() Approved = %v, want %v", got.Approved, tt.wantApproved)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Processor.Process() Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			for _, bit := range tt.wantTVR {
+				if !got.TVR.IsSet(bit) {
+					t.Errorf("Processor.Process() TVR = %v, want %s", got.TVR, bit.Name)
+				}
+			}
+			if got.Cryptogram != tt.wantCrypto {
+				t.Errorf("Processor.Process() Cryptogram = %v, want %v", got.Cryptogram, tt.wantCrypto)
+			}
+			if tt.wantCVM != "" && got.CVM.Hex() != tt.wantCVM {
+				t.Errorf("Processor.Process() CVM = %v, want %v", got.CVM, tt.wantCVM)
+			}
+			if !got.TVR.IsSet(tlv.TVROfflineDataAuthenticationNotPerformed) {
+				t.Errorf("Processor.Process() TVR = %v, want offline data authentication not performed", got.TVR)
+			}
+			if tt.wantTSI != "" && got.TSI.Hex() != tt.wantTSI {
+				t.Errorf("Processor.Process() TSI = %v, want %v", got.TSI, tt.wantTSI)
+			}
+			if got.Brand != domain.BrandVisa {
+				t.Errorf("Processor.Process() Brand = %v, want %v", got.Brand, domain.BrandVisa)
+			}
+			if got.Amount != tt.transaction.Amount() {
+				t.Errorf("Processor.Process() Amount = %v, want %v", got.Amount, tt.transaction.Amount())
+			}
+			if !got.Timestamp.Equal(now) {
+				t.Errorf("Processor.Process() Timestamp = %v, want %v", got.Timestamp, now)
+			}
+		})
+	}
+}
+
+type countingPINPad struct {
+	calls int
+}
+
+func (p *countingPINPad) EnterPIN(method domain.CVMMethod) domain.PINResult {
+	p.calls++
+	return domain.PINVerified
+}
+
+func TestProcessor_Process_CardChecksBeforePIN(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	pinPad := &countingPINPad{}
+	terminal := Terminal{Capabilities: [3]byte{0xE0, 0x80, 0xC8}, PINPad: pinPad, ActionCodes: DefaultTerminal.ActionCodes}
+
+	transaction := &domain.Tlv{
+		Pan:          "4539578763621486",
+		DataValidade: time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC),
+		CVMList: &domain.CVMList{Rules: []domain.CVMRule{
+			{Method: domain.CVMPlaintextPIN, Condition: domain.CVMAlways},
+		}},
+	}
+
+	got, err := NewProcessor(&stubGateway{}, WithClock(clock.Fixed(now)), WithTerminal(terminal)).Process(transaction)
+	if err != nil {
+		t.Fatalf("Processor.Process() error = %v", err)
+	}
+	if got.Approved || !got.TVR.IsSet(tlv.TVRExpiredApplication) {
+		t.Errorf("Processor.Process() Approved = %v, TVR = %v, want declined as expired", got.Approved, got.TVR)
+	}
+	if pinPad.calls != 0 {
+		t.Errorf("PINPad.EnterPIN() calls = %d, want 0 for an expired card", pinPad.calls)
+	}
+	if got.TSI.IsSet(tlv.TSICardholderVerificationPerformed) {
+		t.Errorf("Processor.Process() TSI = %v, want cardholder verification not performed", got.TSI)
+	}
+}
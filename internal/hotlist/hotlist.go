@@ -0,0 +1,241 @@
+// Package hotlist keeps the terminal exception file: the cards that must be
+// declined even when the terminal cannot go online.
+package hotlist
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	maxPANLength = 19
+
+	// expiryLayout is the layout of the expiry column of a hotlist file.
+	expiryLayout = "2006-01-02"
+)
+
+var header = []string{"pan", "sequence", "reason", "expiry"}
+
+// Entry is a blocked card. A nil Sequence blocks every card with the PAN;
+// otherwise only the card with that PAN Sequence Number (5F34) is blocked.
+// The entry no longer applies from ExpiresAt on; a zero ExpiresAt never
+// expires.
+type Entry struct {
+	PAN       string
+	Sequence  *uint8
+	Reason    string
+	ExpiresAt time.Time
+}
+
+// activeAt reports whether the entry still applies at t.
+func (e Entry) activeAt(t time.Time) bool {
+	return e.ExpiresAt.IsZero() || t.Before(e.ExpiresAt)
+}
+
+func (e Entry) sameCard(pan string, sequence *uint8) bool {
+	if e.PAN != pan {
+		return false
+	}
+	if e.Sequence == nil || sequence == nil {
+		return e.Sequence == nil && sequence == nil
+	}
+	return *e.Sequence == *sequence
+}
+
+// Store is an in-memory hotlist indexed by PAN. It is safe for concurrent
+// use; lookups run in constant time regardless of the number of entries.
+type Store struct {
+	mu      sync.RWMutex
+	entries map[string][]Entry
+}
+
+func NewStore() *Store {
+	return &Store{entries: make(map[string][]Entry)}
+}
+
+// LoadFile reads a hotlist file into a new Store. See Load for the format.
+func LoadFile(path string) (*Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hotlist: %w", err)
+	}
+	defer f.Close()
+
+	s := NewStore()
+	if err := s.Load(f); err != nil {
+		return nil, fmt.Errorf("failed to load hotlist %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Load replaces the content of the store with the entries read from r, a
+// CSV file with the header "pan,sequence,reason,expiry". The sequence and
+// expiry columns may be empty; expiry is a date and the entry applies
+// until the end of that day in UTC. The store is left untouched when r
+// holds an invalid entry.
+func (s *Store) Load(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(header)
+
+	first, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("missing header")
+	}
+	if err != nil {
+		return err
+	}
+	for i, name := range header {
+		if first[i] != name {
+			return fmt.Errorf("invalid header %v: expected %v", first, header)
+		}
+	}
+
+	entries := make(map[string][]Entry)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		entry, err := parseRecord(record)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		entries[entry.PAN] = upsert(entries[entry.PAN], entry)
+	}
+
+	s.mu.Lock()
+	s.entries = entries
+	s.mu.Unlock()
+	return nil
+}
+
+func parseRecord(record []string) (Entry, error) {
+	entry := Entry{PAN: record[0], Reason: record[2]}
+
+	if record[1] != "" {
+		n, err := strconv.ParseUint(record[1], 10, 8)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid PAN sequence number %q", record[1])
+		}
+		sequence := uint8(n)
+		entry.Sequence = &sequence
+	}
+
+	if record[3] != "" {
+		expiry, err := time.Parse(expiryLayout, record[3])
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid expiry %q: expected YYYY-MM-DD", record[3])
+		}
+		entry.ExpiresAt = expiry.AddDate(0, 0, 1)
+	}
+
+	if err := validatePAN(entry.PAN); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+func validatePAN(pan string) error {
+	if pan == "" || len(pan) > maxPANLength {
+		return fmt.Errorf("invalid PAN %q: expected 1 to %d digits", pan, maxPANLength)
+	}
+	for _, c := range pan {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("invalid PAN %q: must contain only digits", pan)
+		}
+	}
+	return nil
+}
+
+// upsert adds entry to entries, replacing the entry for the same card.
+func upsert(entries []Entry, entry Entry) []Entry {
+	for i, e := range entries {
+		if e.sameCard(entry.PAN, entry.Sequence) {
+			entries[i] = entry
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
+
+// Add blocks the card of entry, replacing any entry for the same PAN and
+// sequence number.
+func (s *Store) Add(entry Entry) error {
+	if err := validatePAN(entry.PAN); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.PAN] = upsert(s.entries[entry.PAN], entry)
+	return nil
+}
+
+// Remove unblocks the card with the given PAN and sequence number. A nil
+// sequence removes the entry blocking every card with the PAN. It reports
+// whether an entry was removed.
+func (s *Store) Remove(pan string, sequence *uint8) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.entries[pan]
+	for i, e := range entries {
+		if e.sameCard(pan, sequence) {
+			entries = append(entries[:i], entries[i+1:]...)
+			if len(entries) == 0 {
+				delete(s.entries, pan)
+			} else {
+				s.entries[pan] = entries
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the entry blocking the card at t. An entry without
+// sequence number blocks the card whatever its sequence number.
+func (s *Store) Lookup(pan string, sequence *uint8, at time.Time) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.entries[pan] {
+		if !e.activeAt(at) {
+			continue
+		}
+		if e.Sequence == nil || (sequence != nil && *e.Sequence == *sequence) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Contains reports whether the card is blocked at t. It makes a Store
+// usable as a risk.ExceptionFile.
+func (s *Store) Contains(pan string, sequence *uint8, at time.Time) bool {
+	_, ok := s.Lookup(pan, sequence, at)
+	return ok
+}
+
+// Len returns the number of entries, expired ones included.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, entries := range s.entries {
+		n += len(entries)
+	}
+	return n
+}
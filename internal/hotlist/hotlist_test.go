@@ -0,0 +1,175 @@
+package hotlist
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func seq(v uint8) *uint8 { return &v }
+
+const testFile = `pan,sequence,reason,expiry
+4539578763621486,,lost card,
+5555555555554444,01,stolen card,2025-06-30
+6011000990139424,,fraud suspected,2025-06-01
+`
+
+func TestStore_Lookup(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	s := NewStore()
+	if err := s.Load(strings.NewReader(testFile)); err != nil {
+		t.Fatalf("Store.Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		pan        string
+		sequence   *uint8
+		at         time.Time
+		wantReason string
+		wantFound  bool
+	}{
+		{name: "blocked PAN without sequence", pan: "4539578763621486", at: now, wantReason: "lost card", wantFound: true},
+		{name: "entry without sequence blocks every sequence", pan: "4539578763621486", sequence: seq(2), at: now, wantReason: "lost card", wantFound: true},
+		{name: "blocked PAN and sequence", pan: "5555555555554444", sequence: seq(1), at: now, wantReason: "stolen card", wantFound: true},
+		{name: "other sequence not blocked", pan: "5555555555554444", sequence: seq(2), at: now},
+		{name: "card without sequence not blocked by sequence entry", pan: "5555555555554444", at: now},
+		{name: "entry applies until end of expiry day", pan: "5555555555554444", sequence: seq(1), at: time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC), wantReason: "stolen card", wantFound: true},
+		{name: "entry expired", pan: "5555555555554444", sequence: seq(1), at: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "expired entry ignored", pan: "6011000990139424", at: now},
+		{name: "unknown PAN", pan: "4111111111111111", at: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := s.Lookup(tt.pan, tt.sequence, tt.at)
+			if found != tt.wantFound {
+				t.Fatalf("Store.Lookup() found = %v, want %v", found, tt.wantFound)
+			}
+			if got.Reason != tt.wantReason {
+				t.Errorf("Store.Lookup() Reason = %q, want %q", got.Reason, tt.wantReason)
+			}
+			if s.Contains(tt.pan, tt.sequence, tt.at) != tt.wantFound {
+				t.Errorf("Store.Contains() = %v, want %v", !tt.wantFound, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestStore_Load(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "valid file", data: testFile, wantLen: 3},
+		{name: "header only", data: "pan,sequence,reason,expiry\n", wantLen: 0},
+		{name: "same card listed twice keeps one entry", data: "pan,sequence,reason,expiry\n4539578763621486,,lost,\n4539578763621486,,stolen,\n", wantLen: 1},
+		{name: "empty file", data: "", wantErr: true},
+		{name: "wrong header", data: "card,seq,reason,expiry\n", wantErr: true},
+		{name: "missing column", data: "pan,sequence,reason,expiry\n4539578763621486,,lost\n", wantErr: true},
+		{name: "non numeric PAN", data: "pan,sequence,reason,expiry\n4539X78763621486,,lost,\n", wantErr: true},
+		{name: "PAN too long", data: "pan,sequence,reason,expiry\n45395787636214861234,,lost,\n", wantErr: true},
+		{name: "invalid sequence", data: "pan,sequence,reason,expiry\n4539578763621486,256,lost,\n", wantErr: true},
+		{name: "invalid expiry", data: "pan,sequence,reason,expiry\n4539578763621486,,lost,30/06/2025\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			err := s.Load(strings.NewReader(tt.data))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Store.Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && s.Len() != tt.wantLen {
+				t.Errorf("Store.Load() Len = %d, want %d", s.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestStore_Load_InvalidKeepsEntries(t *testing.T) {
+	s := NewStore()
+	if err := s.Load(strings.NewReader(testFile)); err != nil {
+		t.Fatalf("Store.Load() error = %v", err)
+	}
+
+	if err := s.Load(strings.NewReader("pan,sequence,reason,expiry\ninvalid,,lost,\n")); err == nil {
+		t.Fatalf("Store.Load() expected error for invalid PAN")
+	}
+	if s.Len() != 3 {
+		t.Errorf("Store.Load() Len = %d after failed reload, want 3", s.Len())
+	}
+}
+
+func TestStore_AddRemove(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	s := NewStore()
+
+	if err := s.Add(Entry{PAN: "4539578763621486", Reason: "lost card"}); err != nil {
+		t.Fatalf("Store.Add() error = %v", err)
+	}
+	if err := s.Add(Entry{PAN: "4539578763621486", Sequence: seq(1), Reason: "stolen card"}); err != nil {
+		t.Fatalf("Store.Add() error = %v", err)
+	}
+	if err := s.Add(Entry{PAN: "4539578763621486", Reason: "fraud"}); err != nil {
+		t.Fatalf("Store.Add() error = %v", err)
+	}
+	if err := s.Add(Entry{PAN: "not a pan"}); err == nil {
+		t.Errorf("Store.Add() expected error for invalid PAN")
+	}
+
+	if s.Len() != 2 {
+		t.Errorf("Store.Add() Len = %d, want 2", s.Len())
+	}
+	if got, _ := s.Lookup("4539578763621486", nil, now); got.Reason != "fraud" {
+		t.Errorf("Store.Lookup() Reason = %q, want %q", got.Reason, "fraud")
+	}
+
+	if !s.Remove("4539578763621486", nil) {
+		t.Errorf("Store.Remove() = false, want true")
+	}
+	if s.Remove("4539578763621486", nil) {
+		t.Errorf("Store.Remove() second call = true, want false")
+	}
+	if s.Contains("4539578763621486", seq(2), now) {
+		t.Errorf("Store.Contains() sequence 2 still blocked after removing PAN entry")
+	}
+	if !s.Contains("4539578763621486", seq(1), now) {
+		t.Errorf("Store.Contains() sequence 1 no longer blocked")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotlist.csv")
+
+	var sb strings.Builder
+	sb.WriteString("pan,sequence,reason,expiry\n")
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&sb, "4%015d,,bulk,\n", i)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if s.Len() != 200000 {
+		t.Errorf("LoadFile() Len = %d, want 200000", s.Len())
+	}
+	if !s.Contains("4000000000123456", nil, time.Now()) {
+		t.Errorf("LoadFile() entry 4000000000123456 not found")
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Errorf("LoadFile() expected error for missing file")
+	}
+}
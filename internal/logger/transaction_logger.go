@@ -1,34 +1,67 @@
 package logger
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/josuesantos1/emv/internal/clock"
+	domain "github.com/josuesantos1/emv/internal/domain"
 	"github.com/josuesantos1/emv/internal/handlers"
+	"github.com/josuesantos1/emv/pkg/iso"
 )
 
 type Log struct {
-	ID           string    `json:"id"`
-	Pan          string    `json:"pan"`
-	DataValidade string    `json:"data_validade"`
-	CVM          string    `json:"cvm"`
-	Approved     bool      `json:"approved"`
-	Message      string    `json:"message"`
-	Timestamp    time.Time `json:"timestamp"`
+	ID             string    `json:"id"`
+	Pan            string    `json:"pan"`
+	Brand          string    `json:"brand"`
+	DataValidade   string    `json:"data_validade"`
+	CVM            string    `json:"cvm"`
+	CVMDescription string    `json:"cvm_description"`
+	Amount         string    `json:"amount,omitempty"`
+	AmountOther    string    `json:"amount_other,omitempty"`
+	Currency       string    `json:"currency,omitempty"`
+	Country        string    `json:"country,omitempty"`
+	TVR            string    `json:"tvr"`
+	TVRBits        []string  `json:"tvr_bits,omitempty"`
+	TSI            string    `json:"tsi"`
+	TSIBits        []string  `json:"tsi_bits,omitempty"`
+	Cryptogram     string    `json:"cryptogram,omitempty"`
+	ServiceCode    string    `json:"service_code,omitempty"`
+	Approved       bool      `json:"approved"`
+	Message        string    `json:"message"`
+	Conflicts      []string  `json:"conflicts,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
 }
 
 type JSONLogger struct {
 	filePath string
+	clock    clock.Clock
 	mu       sync.Mutex
 }
 
-func NewJSONLogger(filePath string) *JSONLogger {
-	return &JSONLogger{
+// Option configures a JSONLogger.
+type Option func(*JSONLogger)
+
+// WithClock sets the clock used to generate log entry IDs.
+func WithClock(c clock.Clock) Option {
+	return func(l *JSONLogger) {
+		l.clock = c
+	}
+}
+
+func NewJSONLogger(filePath string, opts ...Option) *JSONLogger {
+	l := &JSONLogger{
 		filePath: filePath,
+		clock:    clock.System{},
+	}
+	for _, opt := range opts {
+		opt(l)
 	}
+	return l
 }
 
 func (l *JSONLogger) Log(result *handlers.TransactionResult) error {
@@ -36,13 +69,38 @@ func (l *JSONLogger) Log(result *handlers.TransactionResult) error {
 	defer l.mu.Unlock()
 
 	logEntry := Log{
-		ID:           generateID(),
-		Pan:          result.Pan,
-		DataValidade: result.DataValidade.Format("01/2006"),
-		CVM:          result.CVM,
-		Approved:     result.Approved,
-		Message:      result.Message,
-		Timestamp:    result.Timestamp,
+		ID:             l.generateID(),
+		Pan:            result.Pan,
+		Brand:          result.Brand.String(),
+		DataValidade:   result.DataValidade.Format("01/2006"),
+		CVM:            result.CVM.Hex(),
+		CVMDescription: result.CVM.String(),
+		TVR:            result.TVR.Hex(),
+		TVRBits:        result.TVR.Names(),
+		TSI:            result.TSI.Hex(),
+		TSIBits:        result.TSI.Names(),
+		ServiceCode:    result.ServiceCode,
+		Approved:       result.Approved,
+		Message:        result.Message,
+		Conflicts:      result.Conflicts,
+		Timestamp:      result.Timestamp,
+	}
+
+	if result.Amount.Currency != 0 {
+		logEntry.Amount = result.Amount.Decimal()
+		logEntry.Currency = result.Amount.CurrencyAlpha()
+		if result.AmountOther.Amount > 0 {
+			logEntry.AmountOther = result.AmountOther.Decimal()
+		}
+	}
+	if result.Cryptogram != domain.CryptogramNone {
+		logEntry.Cryptogram = result.Cryptogram.String()
+	}
+	if result.Country != 0 {
+		logEntry.Country = fmt.Sprintf("%03d", result.Country)
+		if c, ok := iso.LookupCountry(result.Country); ok {
+			logEntry.Country = c.Alpha3
+		}
 	}
 
 	var logs []Log
@@ -67,6 +125,10 @@ func (l *JSONLogger) Log(result *handlers.TransactionResult) error {
 	return nil
 }
 
-func generateID() string {
-	return fmt.Sprintf("TRX-%d", time.Now().UnixNano())
+// generateID combines the clock time with a random suffix, so that IDs
+// stay unique when the clock is fixed, as when replaying transactions.
+func (l *JSONLogger) generateID() string {
+	var suffix [8]byte
+	rand.Read(suffix[:])
+	return fmt.Sprintf("TRX-%d-%x", l.clock.Now().UnixNano(), suffix)
 }
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josuesantos1/emv/internal/clock"
+)
+
+func TestJSONLogger_generateID(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	l := NewJSONLogger("transactions.json", WithClock(clock.Fixed(now)))
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := l.generateID()
+		if !strings.HasPrefix(id, "TRX-1749983400000000000-") {
+			t.Fatalf("JSONLogger.generateID() = %q, want prefix with the clock time", id)
+		}
+		if seen[id] {
+			t.Fatalf("JSONLogger.generateID() = %q repeated with a fixed clock", id)
+		}
+		seen[id] = true
+	}
+}
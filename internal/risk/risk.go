@@ -0,0 +1,166 @@
+// Package risk implements terminal risk management as described in EMV 4.3
+// Book 3 §10.6: floor limit checking, random transaction selection,
+// exception file checking and velocity checking.
+package risk
+
+import (
+	"math/rand"
+	"time"
+
+	domain "github.com/josuesantos1/emv/internal/domain"
+	"github.com/josuesantos1/emv/pkg/tlv"
+)
+
+// Config holds the terminal risk management parameters. Amounts are in
+// minor units of the transaction currency.
+type Config struct {
+	// FloorLimit is the Terminal Floor Limit (9F1B). Transactions at or
+	// above it are flagged for online authorization; a zero floor limit
+	// flags every transaction.
+	FloorLimit      uint64
+	RandomSelection RandomSelection
+}
+
+// RandomSelection configures the random selection of transactions below
+// the floor limit for online processing. Below Threshold transactions are
+// selected with TargetPercent probability; from Threshold up to the floor
+// limit the probability grows linearly towards MaxPercent.
+type RandomSelection struct {
+	TargetPercent int
+	MaxPercent    int
+	Threshold     uint64
+}
+
+// ExceptionFile is a list of cards the terminal must not accept, such as a
+// hotlist. sequence is the PAN Sequence Number (5F34), nil when absent.
+type ExceptionFile interface {
+	Contains(pan string, sequence *uint8, at time.Time) bool
+}
+
+// Rand returns pseudo-random numbers in [0, n).
+type Rand interface {
+	Intn(n int) int
+}
+
+// globalRand uses the math/rand top-level source, which is safe for
+// concurrent use.
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+// Manager runs terminal risk management on transactions.
+type Manager struct {
+	config     Config
+	exceptions ExceptionFile
+	rand       Rand
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithExceptionFile sets the exception file checked for the card. By
+// default no exception file is checked.
+func WithExceptionFile(f ExceptionFile) Option {
+	return func(m *Manager) {
+		m.exceptions = f
+	}
+}
+
+// WithRand sets the source of random numbers used for random transaction
+// selection. The default is the math/rand top-level source.
+func WithRand(r Rand) Option {
+	return func(m *Manager) {
+		m.rand = r
+	}
+}
+
+func NewManager(config Config, opts ...Option) *Manager {
+	m := &Manager{
+		config: config,
+		rand:   globalRand{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Check runs every risk management check on transaction, setting the
+// resulting TVR bits and marking terminal risk management as performed in
+// the TSI.
+func (m *Manager) Check(transaction *domain.Tlv, now time.Time) {
+	m.checkFloorLimit(transaction)
+	m.checkRandomSelection(transaction)
+	m.checkExceptionFile(transaction, now)
+	checkVelocity(transaction)
+
+	transaction.TSI.Set(tlv.TSITerminalRiskManagementPerformed)
+}
+
+func (m *Manager) checkFloorLimit(transaction *domain.Tlv) {
+	if transaction.AmountAuthorised >= m.config.FloorLimit {
+		transaction.TVR.Set(tlv.TVRFloorLimitExceeded)
+	}
+}
+
+// checkRandomSelection selects transactions below the floor limit for
+// online processing by comparing a random number from 1 to 99 with the
+// transaction target percentage.
+func (m *Manager) checkRandomSelection(transaction *domain.Tlv) {
+	amount := transaction.AmountAuthorised
+	floor := m.config.FloorLimit
+	selection := m.config.RandomSelection
+
+	if amount >= floor {
+		return
+	}
+
+	percent := float64(selection.TargetPercent)
+	if amount >= selection.Threshold && floor > selection.Threshold {
+		interpolated := float64(amount-selection.Threshold) / float64(floor-selection.Threshold)
+		percent += float64(selection.MaxPercent-selection.TargetPercent) * interpolated
+	}
+
+	if float64(m.rand.Intn(99)+1) <= percent {
+		transaction.TVR.Set(tlv.TVRRandomlySelected)
+	}
+}
+
+func (m *Manager) checkExceptionFile(transaction *domain.Tlv, now time.Time) {
+	if m.exceptions == nil {
+		return
+	}
+	if m.exceptions.Contains(transaction.Pan, transaction.PANSequenceNumber, now) {
+		transaction.TVR.Set(tlv.TVRExceptionFile)
+	}
+}
+
+// checkVelocity compares the number of transactions since the last online
+// one with the consecutive offline limits of the card. It only runs when
+// the card provides both limits; when the counters are missing both limits
+// are considered exceeded.
+func checkVelocity(transaction *domain.Tlv) {
+	if transaction.LowerOfflineLimit == nil || transaction.UpperOfflineLimit == nil {
+		return
+	}
+
+	if transaction.ATC == nil || transaction.LastOnlineATC == nil || *transaction.ATC < *transaction.LastOnlineATC {
+		transaction.TVR.Set(tlv.TVRLowerOfflineLimitExceeded)
+		transaction.TVR.Set(tlv.TVRUpperOfflineLimitExceeded)
+		return
+	}
+
+	if *transaction.LastOnlineATC == 0 {
+		transaction.TVR.Set(tlv.TVRNewCard)
+	}
+
+	offline := *transaction.ATC - *transaction.LastOnlineATC
+	if offline > uint16(*transaction.LowerOfflineLimit) {
+		transaction.TVR.Set(tlv.TVRLowerOfflineLimitExceeded)
+	}
+	if offline > uint16(*transaction.UpperOfflineLimit) {
+		transaction.TVR.Set(tlv.TVRUpperOfflineLimitExceeded)
+	}
+}
@@ -0,0 +1,201 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	domain "github.com/josuesantos1/emv/internal/domain"
+	"github.com/josuesantos1/emv/pkg/tlv"
+)
+
+// fixedRand always draws the same number, so the random number compared
+// with the target percentage is n+1.
+type fixedRand int
+
+func (r fixedRand) Intn(n int) int {
+	return int(r)
+}
+
+type stubExceptionFile map[string]bool
+
+func (f stubExceptionFile) Contains(pan string, sequence *uint8, at time.Time) bool {
+	return f[pan]
+}
+
+func u8(v uint8) *uint8    { return &v }
+func u16(v uint16) *uint16 { return &v }
+
+func TestManager_Check(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	config := Config{
+		FloorLimit: 10000,
+		RandomSelection: RandomSelection{
+			TargetPercent: 20,
+			MaxPercent:    80,
+			Threshold:     5000,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		config      Config
+		transaction domain.Tlv
+		rand        fixedRand
+		exceptions  ExceptionFile
+		want        []tlv.Bit
+	}{
+		{
+			name:        "below floor limit and not selected",
+			config:      config,
+			transaction: domain.Tlv{AmountAuthorised: 1000},
+			rand:        98,
+		},
+		{
+			name:        "amount equal to floor limit",
+			config:      config,
+			transaction: domain.Tlv{AmountAuthorised: 10000},
+			want:        []tlv.Bit{tlv.TVRFloorLimitExceeded},
+		},
+		{
+			name:        "zero floor limit flags every transaction",
+			transaction: domain.Tlv{AmountAuthorised: 0},
+			want:        []tlv.Bit{tlv.TVRFloorLimitExceeded},
+		},
+		{
+			name:        "selected below threshold at target percentage",
+			config:      config,
+			transaction: domain.Tlv{AmountAuthorised: 1000},
+			rand:        19,
+			want:        []tlv.Bit{tlv.TVRRandomlySelected},
+		},
+		{
+			name:        "not selected below threshold above target percentage",
+			config:      config,
+			transaction: domain.Tlv{AmountAuthorised: 1000},
+			rand:        20,
+		},
+		{
+			name:        "selected above threshold with interpolated percentage",
+			config:      config,
+			transaction: domain.Tlv{AmountAuthorised: 7500},
+			rand:        49,
+			want:        []tlv.Bit{tlv.TVRRandomlySelected},
+		},
+		{
+			name:        "not selected above interpolated percentage",
+			config:      config,
+			transaction: domain.Tlv{AmountAuthorised: 7500},
+			rand:        50,
+		},
+		{
+			name:        "card in exception file",
+			config:      config,
+			transaction: domain.Tlv{Pan: "4539578763621486", AmountAuthorised: 1000},
+			rand:        98,
+			exceptions:  stubExceptionFile{"4539578763621486": true},
+			want:        []tlv.Bit{tlv.TVRExceptionFile},
+		},
+		{
+			name:        "card not in exception file",
+			config:      config,
+			transaction: domain.Tlv{Pan: "4539578763621486", AmountAuthorised: 1000},
+			rand:        98,
+			exceptions:  stubExceptionFile{"5555555555554444": true},
+		},
+		{
+			name:   "offline transactions within limits",
+			config: config,
+			transaction: domain.Tlv{
+				AmountAuthorised:  1000,
+				ATC:               u16(12),
+				LastOnlineATC:     u16(10),
+				LowerOfflineLimit: u8(3),
+				UpperOfflineLimit: u8(5),
+			},
+			rand: 98,
+		},
+		{
+			name:   "lower consecutive offline limit exceeded",
+			config: config,
+			transaction: domain.Tlv{
+				AmountAuthorised:  1000,
+				ATC:               u16(14),
+				LastOnlineATC:     u16(10),
+				LowerOfflineLimit: u8(3),
+				UpperOfflineLimit: u8(5),
+			},
+			rand: 98,
+			want: []tlv.Bit{tlv.TVRLowerOfflineLimitExceeded},
+		},
+		{
+			name:   "upper consecutive offline limit exceeded",
+			config: config,
+			transaction: domain.Tlv{
+				AmountAuthorised:  1000,
+				ATC:               u16(16),
+				LastOnlineATC:     u16(10),
+				LowerOfflineLimit: u8(3),
+				UpperOfflineLimit: u8(5),
+			},
+			rand: 98,
+			want: []tlv.Bit{tlv.TVRLowerOfflineLimitExceeded, tlv.TVRUpperOfflineLimitExceeded},
+		},
+		{
+			name:   "new card never went online",
+			config: config,
+			transaction: domain.Tlv{
+				AmountAuthorised:  1000,
+				ATC:               u16(1),
+				LastOnlineATC:     u16(0),
+				LowerOfflineLimit: u8(3),
+				UpperOfflineLimit: u8(5),
+			},
+			rand: 98,
+			want: []tlv.Bit{tlv.TVRNewCard},
+		},
+		{
+			name:   "missing ATC exceeds both limits",
+			config: config,
+			transaction: domain.Tlv{
+				AmountAuthorised:  1000,
+				LastOnlineATC:     u16(10),
+				LowerOfflineLimit: u8(3),
+				UpperOfflineLimit: u8(5),
+			},
+			rand: 98,
+			want: []tlv.Bit{tlv.TVRLowerOfflineLimitExceeded, tlv.TVRUpperOfflineLimitExceeded},
+		},
+		{
+			name:   "velocity skipped without offline limits",
+			config: config,
+			transaction: domain.Tlv{
+				AmountAuthorised: 1000,
+				LastOnlineATC:    u16(0),
+			},
+			rand: 98,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithRand(tt.rand)}
+			if tt.exceptions != nil {
+				opts = append(opts, WithExceptionFile(tt.exceptions))
+			}
+			m := NewManager(tt.config, opts...)
+
+			m.Check(&tt.transaction, now)
+
+			var want domain.TVR
+			for _, bit := range tt.want {
+				want.Set(bit)
+			}
+			if tt.transaction.TVR != want {
+				t.Errorf("Manager.Check() TVR = %v, want %v", tt.transaction.TVR, want)
+			}
+			if !tt.transaction.TSI.IsSet(tlv.TSITerminalRiskManagementPerformed) {
+				t.Errorf("Manager.Check() TSI = %v, want terminal risk management performed", tt.transaction.TSI)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package iso provides the ISO 4217 currency and ISO 3166 country code
+// tables referenced by EMV data elements such as the Transaction Currency
+// Code (5F2A) and the Terminal Country Code (9F1A).
+package iso
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency. MinorUnits is the number of decimal
+// places of the currency, e.g. 2 for BRL and 0 for JPY.
+type Currency struct {
+	Numeric    uint16
+	Alpha      string
+	MinorUnits int
+	Name       string
+}
+
+// Country is an ISO 3166-1 country.
+type Country struct {
+	Numeric uint16
+	Alpha2  string
+	Alpha3  string
+	Name    string
+}
+
+//go:embed currencies.csv
+var currenciesCSV string
+
+//go:embed countries.csv
+var countriesCSV string
+
+var (
+	currencies     = map[uint16]Currency{}
+	currencyAlphas = map[string]Currency{}
+	countries      = map[uint16]Country{}
+)
+
+func init() {
+	for _, record := range mustReadCSV("currencies.csv", currenciesCSV, 4) {
+		minor, err := strconv.Atoi(record[2])
+		if err != nil {
+			panic(fmt.Sprintf("iso: currencies.csv: invalid minor units %q", record[2]))
+		}
+		c := Currency{
+			Numeric:    mustNumeric("currencies.csv", record[0]),
+			Alpha:      record[1],
+			MinorUnits: minor,
+			Name:       record[3],
+		}
+		currencies[c.Numeric] = c
+		currencyAlphas[c.Alpha] = c
+	}
+
+	for _, record := range mustReadCSV("countries.csv", countriesCSV, 4) {
+		c := Country{
+			Numeric: mustNumeric("countries.csv", record[0]),
+			Alpha2:  record[1],
+			Alpha3:  record[2],
+			Name:    record[3],
+		}
+		countries[c.Numeric] = c
+	}
+}
+
+// LookupCurrency returns the currency with the ISO 4217 numeric code.
+func LookupCurrency(numeric uint16) (Currency, bool) {
+	c, ok := currencies[numeric]
+	return c, ok
+}
+
+// LookupCurrencyAlpha returns the currency with the ISO 4217 alphabetic
+// code, e.g. "BRL".
+func LookupCurrencyAlpha(alpha string) (Currency, bool) {
+	c, ok := currencyAlphas[strings.ToUpper(alpha)]
+	return c, ok
+}
+
+// LookupCountry returns the country with the ISO 3166-1 numeric code.
+func LookupCountry(numeric uint16) (Country, bool) {
+	c, ok := countries[numeric]
+	return c, ok
+}
+
+// Format renders an amount in minor units with the currency alphabetic
+// code, e.g. "BRL 12.50".
+func (c Currency) Format(amount uint64) string {
+	return c.Alpha + " " + FormatAmount(amount, c.MinorUnits)
+}
+
+// FormatAmount renders an amount in minor units as a decimal number with
+// exponent decimal places, e.g. 1250 with exponent 2 as "12.50".
+func FormatAmount(amount uint64, exponent int) string {
+	digits := strconv.FormatUint(amount, 10)
+	if exponent <= 0 {
+		return digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	point := len(digits) - exponent
+	return digits[:point] + "." + digits[point:]
+}
+
+func mustReadCSV(name, data string, fields int) [][]string {
+	r := csv.NewReader(strings.NewReader(data))
+	r.FieldsPerRecord = fields
+
+	records, err := r.ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("iso: %s: %v", name, err))
+	}
+	// Skip the header line.
+	return records[1:]
+}
+
+func mustNumeric(name, s string) uint16 {
+	n, err := strconv.ParseUint(s, 10, 16)
+	if err != nil || len(s) != 3 {
+		panic(fmt.Sprintf("iso: %s: invalid numeric code %q", name, s))
+	}
+	return uint16(n)
+}
@@ -0,0 +1,108 @@
+package iso
+
+import "testing"
+
+func TestLookupCurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		numeric   uint16
+		wantAlpha string
+		wantMinor int
+		wantOK    bool
+	}{
+		{name: "brazilian real", numeric: 986, wantAlpha: "BRL", wantMinor: 2, wantOK: true},
+		{name: "yen without minor units", numeric: 392, wantAlpha: "JPY", wantMinor: 0, wantOK: true},
+		{name: "kuwaiti dinar with three decimals", numeric: 414, wantAlpha: "KWD", wantMinor: 3, wantOK: true},
+		{name: "euro", numeric: 978, wantAlpha: "EUR", wantMinor: 2, wantOK: true},
+		{name: "no currency code", numeric: 999, wantOK: false},
+		{name: "unassigned", numeric: 1, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LookupCurrency(tt.numeric)
+			if ok != tt.wantOK {
+				t.Fatalf("LookupCurrency(%d) ok = %v, want %v", tt.numeric, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Alpha != tt.wantAlpha {
+				t.Errorf("LookupCurrency(%d) Alpha = %v, want %v", tt.numeric, got.Alpha, tt.wantAlpha)
+			}
+			if got.MinorUnits != tt.wantMinor {
+				t.Errorf("LookupCurrency(%d) MinorUnits = %v, want %v", tt.numeric, got.MinorUnits, tt.wantMinor)
+			}
+		})
+	}
+}
+
+func TestLookupCurrencyAlpha(t *testing.T) {
+	got, ok := LookupCurrencyAlpha("brl")
+	if !ok || got.Numeric != 986 {
+		t.Errorf("LookupCurrencyAlpha(brl) = %v, %v, want 986, true", got.Numeric, ok)
+	}
+	if _, ok := LookupCurrencyAlpha("ZZZ"); ok {
+		t.Errorf("LookupCurrencyAlpha(ZZZ) ok = true, want false")
+	}
+}
+
+func TestLookupCountry(t *testing.T) {
+	tests := []struct {
+		name       string
+		numeric    uint16
+		wantAlpha2 string
+		wantAlpha3 string
+		wantOK     bool
+	}{
+		{name: "brazil", numeric: 76, wantAlpha2: "BR", wantAlpha3: "BRA", wantOK: true},
+		{name: "united states", numeric: 840, wantAlpha2: "US", wantAlpha3: "USA", wantOK: true},
+		{name: "unassigned", numeric: 999, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LookupCountry(tt.numeric)
+			if ok != tt.wantOK {
+				t.Fatalf("LookupCountry(%d) ok = %v, want %v", tt.numeric, ok, tt.wantOK)
+			}
+			if ok && (got.Alpha2 != tt.wantAlpha2 || got.Alpha3 != tt.wantAlpha3) {
+				t.Errorf("LookupCountry(%d) = %s/%s, want %s/%s", tt.numeric, got.Alpha2, got.Alpha3, tt.wantAlpha2, tt.wantAlpha3)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   uint64
+		exponent int
+		want     string
+	}{
+		{name: "two decimals", amount: 1250, exponent: 2, want: "12.50"},
+		{name: "less than one unit", amount: 5, exponent: 2, want: "0.05"},
+		{name: "zero", amount: 0, exponent: 2, want: "0.00"},
+		{name: "no decimals", amount: 1250, exponent: 0, want: "1250"},
+		{name: "three decimals", amount: 1250, exponent: 3, want: "1.250"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatAmount(tt.amount, tt.exponent); got != tt.want {
+				t.Errorf("FormatAmount(%d, %d) = %v, want %v", tt.amount, tt.exponent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrency_Format(t *testing.T) {
+	brl, _ := LookupCurrency(986)
+	if got := brl.Format(1250); got != "BRL 12.50" {
+		t.Errorf("Currency.Format() = %v, want BRL 12.50", got)
+	}
+	jpy, _ := LookupCurrency(392)
+	if got := jpy.Format(1250); got != "JPY 1250" {
+		t.Errorf("Currency.Format() = %v, want JPY 1250", got)
+	}
+}
@@ -0,0 +1,113 @@
+package tlv
+
+// Bit names a single bit of a fixed length bitmap data element. Byte is
+// 1-based and Mask selects the bit within that byte, following the EMV
+// convention where b8 is the most significant bit.
+type Bit struct {
+	Byte int
+	Mask byte
+	Name string
+}
+
+// IsSet reports whether the bit is set in value.
+func (b Bit) IsSet(value []byte) bool {
+	return b.Byte >= 1 && b.Byte <= len(value) && value[b.Byte-1]&b.Mask != 0
+}
+
+// Terminal Verification Results (95) bits, EMV 4.3 Book 3 Annex C5.
+var (
+	TVROfflineDataAuthenticationNotPerformed = Bit{1, 0x80, "Offline data authentication was not performed"}
+	TVRSDAFailed                             = Bit{1, 0x40, "SDA failed"}
+	TVRICCDataMissing                        = Bit{1, 0x20, "ICC data missing"}
+	TVRExceptionFile                         = Bit{1, 0x10, "Card appears on terminal exception file"}
+	TVRDDAFailed                             = Bit{1, 0x08, "DDA failed"}
+	TVRCDAFailed                             = Bit{1, 0x04, "CDA failed"}
+	TVRSDASelected                           = Bit{1, 0x02, "SDA selected"}
+	TVRDifferentVersions                     = Bit{2, 0x80, "ICC and terminal have different application versions"}
+	TVRExpiredApplication                    = Bit{2, 0x40, "Expired application"}
+	TVRNotYetEffective                       = Bit{2, 0x20, "Application not yet effective"}
+	TVRServiceNotAllowed                     = Bit{2, 0x10, "Requested service not allowed for card product"}
+	TVRNewCard                               = Bit{2, 0x08, "New card"}
+	TVRCVMUnsuccessful                       = Bit{3, 0x80, "Cardholder verification was not successful"}
+	TVRUnrecognisedCVM                       = Bit{3, 0x40, "Unrecognised CVM"}
+	TVRPINTryLimitExceeded                   = Bit{3, 0x20, "PIN Try Limit exceeded"}
+	TVRPINPadMissing                         = Bit{3, 0x10, "PIN entry required and PIN pad not present or not working"}
+	TVRPINNotEntered                         = Bit{3, 0x08, "PIN entry required, PIN pad present, but PIN was not entered"}
+	TVROnlinePINEntered                      = Bit{3, 0x04, "Online PIN entered"}
+	TVRFloorLimitExceeded                    = Bit{4, 0x80, "Transaction exceeds floor limit"}
+	TVRLowerOfflineLimitExceeded             = Bit{4, 0x40, "Lower consecutive offline limit exceeded"}
+	TVRUpperOfflineLimitExceeded             = Bit{4, 0x20, "Upper consecutive offline limit exceeded"}
+	TVRRandomlySelected                      = Bit{4, 0x10, "Transaction selected randomly for online processing"}
+	TVRMerchantForcedOnline                  = Bit{4, 0x08, "Merchant forced transaction online"}
+	TVRDefaultTDOLUsed                       = Bit{5, 0x80, "Default TDOL used"}
+	TVRIssuerAuthenticationFailed            = Bit{5, 0x40, "Issuer authentication failed"}
+	TVRScriptFailedBeforeFinalAC             = Bit{5, 0x20, "Script processing failed before final GENERATE AC"}
+	TVRScriptFailedAfterFinalAC              = Bit{5, 0x10, "Script processing failed after final GENERATE AC"}
+)
+
+// TVRBits describes the Terminal Verification Results (95).
+var TVRBits = []Bit{
+	TVROfflineDataAuthenticationNotPerformed,
+	TVRSDAFailed,
+	TVRICCDataMissing,
+	TVRExceptionFile,
+	TVRDDAFailed,
+	TVRCDAFailed,
+	TVRSDASelected,
+	TVRDifferentVersions,
+	TVRExpiredApplication,
+	TVRNotYetEffective,
+	TVRServiceNotAllowed,
+	TVRNewCard,
+	TVRCVMUnsuccessful,
+	TVRUnrecognisedCVM,
+	TVRPINTryLimitExceeded,
+	TVRPINPadMissing,
+	TVRPINNotEntered,
+	TVROnlinePINEntered,
+	TVRFloorLimitExceeded,
+	TVRLowerOfflineLimitExceeded,
+	TVRUpperOfflineLimitExceeded,
+	TVRRandomlySelected,
+	TVRMerchantForcedOnline,
+	TVRDefaultTDOLUsed,
+	TVRIssuerAuthenticationFailed,
+	TVRScriptFailedBeforeFinalAC,
+	TVRScriptFailedAfterFinalAC,
+}
+
+// Transaction Status Information (9B) bits, EMV 4.3 Book 3 Annex C6.
+var (
+	TSIOfflineDataAuthenticationPerformed = Bit{1, 0x80, "Offline data authentication was performed"}
+	TSICardholderVerificationPerformed    = Bit{1, 0x40, "Cardholder verification was performed"}
+	TSICardRiskManagementPerformed        = Bit{1, 0x20, "Card risk management was performed"}
+	TSIIssuerAuthenticationPerformed      = Bit{1, 0x10, "Issuer authentication was performed"}
+	TSITerminalRiskManagementPerformed    = Bit{1, 0x08, "Terminal risk management was performed"}
+	TSIScriptProcessingPerformed          = Bit{1, 0x04, "Script processing was performed"}
+)
+
+// TSIBits describes the Transaction Status Information (9B).
+var TSIBits = []Bit{
+	TSIOfflineDataAuthenticationPerformed,
+	TSICardholderVerificationPerformed,
+	TSICardRiskManagementPerformed,
+	TSIIssuerAuthenticationPerformed,
+	TSITerminalRiskManagementPerformed,
+	TSIScriptProcessingPerformed,
+}
+
+// AIPBits describes the Application Interchange Profile (82), EMV 4.3 Book 3 Annex C1.
+var AIPBits = []Bit{
+	{1, 0x40, "SDA supported"},
+	{1, 0x20, "DDA supported"},
+	{1, 0x10, "Cardholder verification is supported"},
+	{1, 0x08, "Terminal risk management is to be performed"},
+	{1, 0x04, "Issuer authentication is supported"},
+	{1, 0x01, "CDA supported"},
+}
+
+var bitmaps = map[Tag][]Bit{
+	TagAIP: AIPBits,
+	TagTVR: TVRBits,
+	TagTSI: TSIBits,
+}
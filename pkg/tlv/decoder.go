@@ -0,0 +1,135 @@
+package tlv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// DefaultDecoderMaxLength bounds the value size a Decoder will buffer when
+// no WithMaxLength option is given.
+const DefaultDecoderMaxLength = 64 * 1024
+
+// Decoder reads TLV data objects incrementally from an io.Reader, such as
+// a recorded card session or APDU trace, without loading the whole input
+// in memory. Only one top-level data object is buffered at a time.
+type Decoder struct {
+	r      *bufio.Reader
+	parser *Parser
+	offset int
+}
+
+// NewDecoder returns a Decoder reading from r. It accepts the same options
+// as NewParser; the value length defaults to DefaultDecoderMaxLength.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	parser := NewParser(opts...)
+	if parser.maxLength == 0 {
+		parser.maxLength = DefaultDecoderMaxLength
+	}
+
+	return &Decoder{
+		r:      bufio.NewReader(r),
+		parser: parser,
+	}
+}
+
+// Offset returns the number of bytes consumed from the reader so far.
+func (d *Decoder) Offset() int {
+	return d.offset
+}
+
+// Next returns the next top-level data object, with its templates fully
+// decoded. It returns io.EOF when the input ends cleanly between data
+// objects and a *ParseError when it ends in the middle of one.
+func (d *Decoder) Next() (TLV, error) {
+	if err := d.skipPadding(); err != nil {
+		return TLV{}, err
+	}
+
+	buf, err := d.peek(maxTagBytes)
+	if err != nil {
+		return TLV{}, err
+	}
+	if len(buf) == 0 {
+		return TLV{}, io.EOF
+	}
+
+	tag, used, perr := d.parser.parseTag(buf)
+	if perr != nil {
+		return TLV{}, perr.at(d.offset, 0)
+	}
+	d.discard(used)
+
+	buf, err = d.peek(1 + maxLengthOctets)
+	if err != nil {
+		return TLV{}, err
+	}
+
+	length, used, perr := d.parser.parseLength(buf)
+	if perr != nil {
+		return TLV{}, perr.at(d.offset, tag)
+	}
+	d.discard(used)
+
+	value := make([]byte, length)
+	n, err := io.ReadFull(d.r, value)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return TLV{}, newParseError(ErrValueOverflow, d.offset, tag, "value length %d but only %d bytes left", length, n)
+		}
+		return TLV{}, err
+	}
+
+	item := TLV{
+		Tag:   tag,
+		Len:   length,
+		Value: value,
+	}
+
+	if item.IsConstructed() {
+		children, perr := d.parser.parseChildren(value, d.offset, tag, 1)
+		if perr != nil {
+			return TLV{}, perr
+		}
+		item.Children = children
+	}
+
+	d.offset += length
+
+	return item, nil
+}
+
+func (d *Decoder) skipPadding() error {
+	if d.parser.mode != ModeLenient {
+		return nil
+	}
+
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if !isPadding(b) {
+			return d.r.UnreadByte()
+		}
+		d.offset++
+	}
+}
+
+// peek returns up to n buffered bytes; fewer are returned only at the end
+// of the input.
+func (d *Decoder) peek(n int) ([]byte, error) {
+	buf, err := d.r.Peek(n)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (d *Decoder) discard(n int) {
+	_, _ = d.r.Discard(n)
+	d.offset += n
+}
@@ -0,0 +1,170 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_Next(t *testing.T) {
+	data := hexToBytes("70105A0845395787636214865F24032512319F3403420000")
+	decoder := NewDecoder(iotest.OneByteReader(bytes.NewReader(data)))
+
+	first, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Decoder.Next() unexpected error = %v", err)
+	}
+	if first.Tag != 0x70 || len(first.Children) != 2 {
+		t.Errorf("Decoder.Next() = %v with %d children, want 70 with 2 children", first.Tag, len(first.Children))
+	}
+	if decoder.Offset() != 18 {
+		t.Errorf("Decoder.Offset() = %d, want 18", decoder.Offset())
+	}
+
+	second, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Decoder.Next() unexpected error = %v", err)
+	}
+	if second.Tag != 0x9F34 || second.ValueHex() != "420000" {
+		t.Errorf("Decoder.Next() = %v/%s, want 9F34/420000", second.Tag, second.ValueHex())
+	}
+
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("Decoder.Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestDecoder_Next_MatchesParser(t *testing.T) {
+	data := hexToBytes("6F1A840E315041592E5359532E4444463031A5088801025F2D02656E9F3403420000")
+
+	parser := &Parser{}
+	want, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Parser.Parse() unexpected error = %v", err)
+	}
+
+	decoder := NewDecoder(bytes.NewReader(data))
+	var got []TLV
+	for {
+		item, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decoder.Next() unexpected error = %v", err)
+		}
+		got = append(got, item)
+	}
+
+	if DumpString(got) != DumpString(want) {
+		t.Errorf("Decoder output =\n%s\nwant\n%s", DumpString(got), DumpString(want))
+	}
+}
+
+func TestDecoder_Next_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		opts       []Option
+		wantKind   error
+		wantOffset int
+	}{
+		{
+			name:       "truncated tag",
+			input:      "9F",
+			wantKind:   ErrTruncatedTag,
+			wantOffset: 1,
+		},
+		{
+			name:       "missing length",
+			input:      "9F34",
+			wantKind:   ErrTruncatedLength,
+			wantOffset: 2,
+		},
+		{
+			name:       "truncated value",
+			input:      "5A081234",
+			wantKind:   ErrValueOverflow,
+			wantOffset: 2,
+		},
+		{
+			name:       "value above default maximum",
+			input:      "5A83020000",
+			wantKind:   ErrLengthTooLarge,
+			wantOffset: 1,
+		},
+		{
+			name:       "value above configured maximum",
+			input:      "5A0845395787636214",
+			opts:       []Option{WithMaxLength(4)},
+			wantKind:   ErrLengthTooLarge,
+			wantOffset: 1,
+		},
+		{
+			name:       "templates nested too deep",
+			input:      "70067704A5028800",
+			opts:       []Option{WithMaxDepth(3)},
+			wantKind:   ErrDepthExceeded,
+			wantOffset: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(bytes.NewReader(hexToBytes(tt.input)), tt.opts...)
+
+			_, err := decoder.Next()
+
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("Decoder.Next() error = %v, want kind %v", err, tt.wantKind)
+			}
+
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Decoder.Next() error %T is not a *ParseError", err)
+			}
+			if perr.Offset != tt.wantOffset {
+				t.Errorf("ParseError.Offset = %d, want %d", perr.Offset, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestDecoder_Next_LenientPadding(t *testing.T) {
+	data := hexToBytes("00005A084539578763621486FFFF9F3403420000FF")
+	decoder := NewDecoder(bytes.NewReader(data), WithMode(ModeLenient))
+
+	var tags []Tag
+	for {
+		item, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decoder.Next() unexpected error = %v", err)
+		}
+		tags = append(tags, item.Tag)
+	}
+
+	if len(tags) != 2 || tags[0] != 0x5A || tags[1] != 0x9F34 {
+		t.Errorf("Decoder tags = %v, want [5A 9F34]", tags)
+	}
+	if decoder.Offset() != len(data) {
+		t.Errorf("Decoder.Offset() = %d, want %d", decoder.Offset(), len(data))
+	}
+}
+
+func TestParser_Parse_MaxDepth(t *testing.T) {
+	data := hexToBytes("70067704A5028800")
+
+	if _, err := NewParser(WithMaxDepth(4)).Parse(data); err != nil {
+		t.Errorf("Parser.Parse() with depth 4 unexpected error = %v", err)
+	}
+
+	_, err := NewParser(WithMaxDepth(3)).Parse(data)
+	if !errors.Is(err, ErrDepthExceeded) {
+		t.Errorf("Parser.Parse() with depth 3 error = %v, want %v", err, ErrDepthExceeded)
+	}
+}
@@ -0,0 +1,116 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MaxDOLDataLength caps the length of a single DOL entry and the total
+// length of the data a DOL requests. DOL data travels in the data field of
+// a short command APDU, which holds at most 255 bytes.
+const MaxDOLDataLength = 255
+
+// DOLEntry is a single tag-length pair of a Data Object List such as the
+// PDOL (9F38), CDOL1 (8C), CDOL2 (8D), DDOL (9F49) or TDOL (97).
+type DOLEntry struct {
+	Tag Tag
+	Len int
+}
+
+// ParseDOL decodes a Data Object List, which carries tags and lengths but
+// no values. DOLs requesting more than MaxDOLDataLength bytes are rejected.
+func (p *Parser) ParseDOL(data []byte) ([]DOLEntry, error) {
+	result := make([]DOLEntry, 0, len(data)/2)
+	pos, total := 0, 0
+
+	for pos < len(data) {
+		tag, usedTag, err := p.parseTag(data[pos:])
+		if err != nil {
+			return nil, err.at(pos, 0)
+		}
+		pos += usedTag
+
+		L, usedLen, err := p.parseLength(data[pos:])
+		if err != nil {
+			return nil, err.at(pos, tag)
+		}
+
+		total += L
+		if total > MaxDOLDataLength {
+			return nil, newParseError(ErrLengthTooLarge, pos, tag, "DOL requests more than %d bytes", MaxDOLDataLength)
+		}
+		pos += usedLen
+
+		result = append(result, DOLEntry{Tag: tag, Len: L})
+	}
+
+	return result, nil
+}
+
+// BuildDOL concatenates the values requested by a DOL, taken from values
+// keyed by tag, following EMV 4.3 Book 3 Section 5.4:
+//   - values longer than requested keep their rightmost bytes for numeric
+//     (n) data and their leftmost bytes otherwise;
+//   - values shorter than requested are padded with leading zeros for
+//     numeric data, trailing 'FF's for compressed numeric data and
+//     trailing zeros otherwise;
+//   - tags missing from the dictionary are handled as binary data;
+//   - missing and constructed data objects are filled with zeros.
+//
+// DOLs with negative lengths or requesting more than MaxDOLDataLength
+// bytes are rejected.
+func BuildDOL(dol []DOLEntry, values map[Tag][]byte) ([]byte, error) {
+	total := 0
+	for _, entry := range dol {
+		if entry.Len < 0 {
+			return nil, fmt.Errorf("invalid DOL entry %s: negative length %d", entry.Tag, entry.Len)
+		}
+		total += entry.Len
+		if total > MaxDOLDataLength {
+			return nil, fmt.Errorf("invalid DOL: requests more than %d bytes", MaxDOLDataLength)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range dol {
+		buf.Write(dolValue(entry, values))
+	}
+
+	return buf.Bytes(), nil
+}
+
+func dolValue(entry DOLEntry, values map[Tag][]byte) []byte {
+	out := make([]byte, entry.Len)
+
+	value, present := values[entry.Tag]
+	info, known := LookupTag(entry.Tag)
+	if !known {
+		info = TagInfo{Format: FormatB, Constructed: entry.Tag.IsConstructed()}
+	}
+	if info.Constructed || !present {
+		return out
+	}
+
+	if len(value) >= entry.Len {
+		if info.Format == FormatN {
+			copy(out, value[len(value)-entry.Len:])
+		} else {
+			copy(out, value[:entry.Len])
+		}
+		return out
+	}
+
+	switch info.Format {
+	case FormatN:
+		copy(out[entry.Len-len(value):], value)
+	case FormatCN:
+		copy(out, value)
+		for i := len(value); i < entry.Len; i++ {
+			out[i] = 0xFF
+		}
+	default:
+		copy(out, value)
+	}
+
+	return out
+}
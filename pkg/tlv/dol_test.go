@@ -0,0 +1,194 @@
+package tlv
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestParser_ParseDOL(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []DOLEntry
+		wantErr bool
+	}{
+		{
+			name:  "PDOL with TTQ, amount, country and currency",
+			input: "9F66049F02069F1A025F2A02",
+			want: []DOLEntry{
+				{Tag: 0x9F66, Len: 4},
+				{Tag: 0x9F02, Len: 6},
+				{Tag: 0x9F1A, Len: 2},
+				{Tag: 0x5F2A, Len: 2},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty DOL",
+			input:   "",
+			want:    []DOLEntry{},
+			wantErr: false,
+		},
+		{
+			name:    "entry longer than a command APDU",
+			input:   "9F668401000000",
+			wantErr: true,
+		},
+		{
+			name:    "entries adding up to more than a command APDU",
+			input:   "9F6681809F028180",
+			wantErr: true,
+		},
+		{
+			name:  "entries adding up to exactly a command APDU",
+			input: "9F6681809F027F",
+			want: []DOLEntry{
+				{Tag: 0x9F66, Len: 128},
+				{Tag: 0x9F02, Len: 127},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing length",
+			input:   "9F66049F02",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{}
+
+			got, err := parser.ParseDOL(hexToBytes(tt.input))
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.ParseDOL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr {
+				if len(got) != len(tt.want) {
+					t.Fatalf("Parser.ParseDOL() returned %d entries, want %d", len(got), len(tt.want))
+				}
+				for i := range got {
+					if got[i].Tag != tt.want[i].Tag || got[i].Len != tt.want[i].Len {
+						t.Errorf("Parser.ParseDOL()[%d] = %s/%d, want %s/%d", i, got[i].Tag, got[i].Len, tt.want[i].Tag, tt.want[i].Len)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildDOL(t *testing.T) {
+	tests := []struct {
+		name    string
+		dol     []DOLEntry
+		values  map[Tag][]byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "exact lengths",
+			dol: []DOLEntry{
+				{Tag: 0x9F02, Len: 6},
+				{Tag: 0x5F2A, Len: 2},
+			},
+			values: map[Tag][]byte{
+				TagAmountAuthorised: hexToBytes("000000001250"),
+				0x5F2A:              hexToBytes("0986"),
+			},
+			want: "0000000012500986",
+		},
+		{
+			name:   "numeric padded with leading zeros",
+			dol:    []DOLEntry{{Tag: 0x9F02, Len: 6}},
+			values: map[Tag][]byte{0x9F02: hexToBytes("1250")},
+			want:   "000000001250",
+		},
+		{
+			name:   "numeric truncated keeps rightmost bytes",
+			dol:    []DOLEntry{{Tag: 0x9F02, Len: 2}},
+			values: map[Tag][]byte{0x9F02: hexToBytes("000000001250")},
+			want:   "1250",
+		},
+		{
+			name:   "compressed numeric padded with trailing FF",
+			dol:    []DOLEntry{{Tag: 0x5A, Len: 10}},
+			values: map[Tag][]byte{0x5A: hexToBytes("4539578763621486")},
+			want:   "4539578763621486FFFF",
+		},
+		{
+			name:   "binary padded with trailing zeros",
+			dol:    []DOLEntry{{Tag: 0x9F37, Len: 6}},
+			values: map[Tag][]byte{0x9F37: hexToBytes("AABBCCDD")},
+			want:   "AABBCCDD0000",
+		},
+		{
+			name:   "binary truncated keeps leftmost bytes",
+			dol:    []DOLEntry{{Tag: 0x9F37, Len: 2}},
+			values: map[Tag][]byte{0x9F37: hexToBytes("AABBCCDD")},
+			want:   "AABB",
+		},
+		{
+			name: "missing and constructed objects filled with zeros",
+			dol: []DOLEntry{
+				{Tag: 0x9F66, Len: 4},
+				{Tag: 0x70, Len: 1},
+				{Tag: 0xFF01, Len: 1},
+			},
+			values: map[Tag][]byte{
+				0x70:   hexToBytes("FF"),
+				0xFF01: hexToBytes("FF"),
+			},
+			want: "000000000000",
+		},
+		{
+			name: "unknown tags use the supplied value as binary data",
+			dol: []DOLEntry{
+				{Tag: 0xDF7F, Len: 2},
+				{Tag: 0xDF7E, Len: 4},
+				{Tag: 0xDF7D, Len: 1},
+				{Tag: 0xDF7C, Len: 2},
+			},
+			values: map[Tag][]byte{
+				0xDF7F: hexToBytes("ABCD"),
+				0xDF7E: hexToBytes("ABCD"),
+				0xDF7D: hexToBytes("ABCD"),
+			},
+			want: "ABCDABCD0000AB0000",
+		},
+		{
+			name:    "entry longer than a command APDU",
+			dol:     []DOLEntry{{Tag: 0x9F66, Len: 1 << 24}},
+			wantErr: true,
+		},
+		{
+			name: "entries adding up to more than a command APDU",
+			dol: []DOLEntry{
+				{Tag: 0x9F66, Len: 200},
+				{Tag: 0x9F02, Len: 56},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "negative length",
+			dol:     []DOLEntry{{Tag: 0x9F66, Len: -1}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := BuildDOL(tt.dol, tt.values)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildDOL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.ToUpper(hex.EncodeToString(data)); got != tt.want {
+				t.Errorf("BuildDOL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package tlv
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/josuesantos1/emv/pkg/iso"
+)
+
+const dumpIndent = "  "
+
+var dateTags = map[Tag]struct{}{
+	TagExpirationDate:  {},
+	TagEffectiveDate:   {},
+	TagTransactionDate: {},
+}
+
+var currencyTags = map[Tag]struct{}{
+	TagTransactionCurrency: {},
+	TagApplicationCurrency: {},
+}
+
+var countryTags = map[Tag]struct{}{
+	TagIssuerCountry:   {},
+	TagTerminalCountry: {},
+}
+
+var amountTags = map[Tag]struct{}{
+	TagAmountAuthorised: {},
+	TagAmountOther:      {},
+}
+
+// Dump writes an indented, human readable tree of the TLVs to w: tag,
+// dictionary name, length and raw value, followed by a decoded value when
+// the format is known and, for bitmaps such as the TVR, the bits set.
+func Dump(w io.Writer, tlvs []TLV) error {
+	_, err := io.WriteString(w, DumpString(tlvs))
+	return err
+}
+
+// DumpString returns the output of Dump as a string. Amounts are shown in
+// the Transaction Currency (5F2A) when it is present and known.
+func DumpString(tlvs []TLV) string {
+	var currency *iso.Currency
+	if t, ok := Find(tlvs, TagTransactionCurrency); ok {
+		if c, ok := lookupCurrency(t.Value); ok {
+			currency = &c
+		}
+	}
+
+	var sb strings.Builder
+	dumpLevel(&sb, tlvs, 0, currency)
+	return sb.String()
+}
+
+func dumpLevel(sb *strings.Builder, tlvs []TLV, depth int, currency *iso.Currency) {
+	indent := strings.Repeat(dumpIndent, depth)
+
+	for _, t := range tlvs {
+		info, known := LookupTag(t.Tag)
+
+		name := "Unknown tag"
+		if known {
+			name = info.Name
+		}
+
+		if t.IsConstructed() {
+			fmt.Fprintf(sb, "%s%s %s (%d)\n", indent, t.Tag, name, len(t.Value))
+			dumpLevel(sb, t.Children, depth+1, currency)
+			continue
+		}
+
+		fmt.Fprintf(sb, "%s%s %s (%d): %s", indent, t.Tag, name, len(t.Value), t.ValueHex())
+		if known {
+			decoded := decodeValue(t.Tag, info.Format, t.Value)
+			if _, ok := amountTags[t.Tag]; ok && currency != nil && decoded != "" {
+				decoded = decodeAmount(t.Value, *currency)
+			}
+			if decoded != "" {
+				fmt.Fprintf(sb, " [%s]", decoded)
+			}
+		}
+		sb.WriteString("\n")
+
+		for _, bit := range bitmaps[t.Tag] {
+			if bit.IsSet(t.Value) {
+				fmt.Fprintf(sb, "%s%s- %s\n", indent, dumpIndent, bit.Name)
+			}
+		}
+	}
+}
+
+func decodeValue(tag Tag, format Format, value []byte) string {
+	if len(value) == 0 {
+		return ""
+	}
+
+	if _, ok := dateTags[tag]; ok {
+		date, err := decodeDate(value, false, DefaultCenturyPivot)
+		if err != nil {
+			return ""
+		}
+		return date.Format("2006-01-02")
+	}
+
+	if _, ok := currencyTags[tag]; ok {
+		if c, ok := lookupCurrency(value); ok {
+			return fmt.Sprintf("%03d %s", c.Numeric, c.Alpha)
+		}
+	}
+
+	if _, ok := countryTags[tag]; ok {
+		if digits, ok := bcdDigits(value); ok {
+			if n, err := strconv.ParseUint(digits, 10, 16); err == nil {
+				if c, ok := iso.LookupCountry(uint16(n)); ok {
+					return fmt.Sprintf("%03d %s", c.Numeric, c.Alpha3)
+				}
+			}
+		}
+	}
+
+	switch format {
+	case FormatN:
+		digits, ok := bcdDigits(value)
+		if !ok {
+			return ""
+		}
+		trimmed := strings.TrimLeft(digits, "0")
+		if trimmed == "" {
+			trimmed = "0"
+		}
+		return trimmed
+	case FormatCN:
+		digits, ok := cnDigits(value)
+		if !ok {
+			return ""
+		}
+		return digits
+	case FormatA, FormatAN, FormatANS:
+		if !isPrintable(value) {
+			return ""
+		}
+		return fmt.Sprintf("%q", string(value))
+	}
+
+	return ""
+}
+
+// lookupCurrency decodes an n3 currency code and looks it up in ISO 4217.
+func lookupCurrency(value []byte) (iso.Currency, bool) {
+	digits, ok := bcdDigits(value)
+	if !ok {
+		return iso.Currency{}, false
+	}
+	n, err := strconv.ParseUint(digits, 10, 16)
+	if err != nil {
+		return iso.Currency{}, false
+	}
+	return iso.LookupCurrency(uint16(n))
+}
+
+// decodeAmount renders an n12 amount in the minor units of currency.
+func decodeAmount(value []byte, currency iso.Currency) string {
+	digits, ok := bcdDigits(value)
+	if !ok {
+		return ""
+	}
+	amount, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return ""
+	}
+	return currency.Format(amount)
+}
+
+// bcdDigits returns the decimal digits of a BCD encoded value, or false if
+// any nibble is not a decimal digit.
+func bcdDigits(value []byte) (string, bool) {
+	digits := make([]byte, 0, len(value)*2)
+	for _, b := range value {
+		hi, lo := b>>4, b&0x0F
+		if hi > 9 || lo > 9 {
+			return "", false
+		}
+		digits = append(digits, '0'+hi, '0'+lo)
+	}
+	return string(digits), true
+}
+
+// cnDigits returns the digits of a compressed numeric value, dropping the
+// trailing 'F' padding nibbles.
+func cnDigits(value []byte) (string, bool) {
+	digits := make([]byte, 0, len(value)*2)
+	padding := false
+	for _, b := range value {
+		for _, nibble := range []byte{b >> 4, b & 0x0F} {
+			switch {
+			case nibble == 0x0F:
+				padding = true
+			case padding || nibble > 9:
+				return "", false
+			default:
+				digits = append(digits, '0'+nibble)
+			}
+		}
+	}
+	return string(digits), true
+}
+
+func isPrintable(value []byte) bool {
+	for _, b := range value {
+		if b < 0x20 || b > 0x7E {
+			return false
+		}
+	}
+	return true
+}
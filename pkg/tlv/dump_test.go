@@ -0,0 +1,86 @@
+package tlv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDumpString(t *testing.T) {
+	parser := &Parser{}
+	tlvs, err := parser.Parse(hexToBytes("70165A0845395787636214865F240325123150045649534195050000008000DF7F0101"))
+	if err != nil {
+		t.Fatalf("Parser.Parse() unexpected error = %v", err)
+	}
+
+	got := DumpString(tlvs)
+
+	want := strings.Join([]string{
+		"70 READ RECORD Response Message Template (22)",
+		"  5A Application PAN (8): 4539578763621486 [4539578763621486]",
+		"  5F24 Application Expiration Date (3): 251231 [2025-12-31]",
+		"  50 Application Label (4): 56495341 [\"VISA\"]",
+		"95 Terminal Verification Results (5): 0000008000",
+		"  - Transaction exceeds floor limit",
+		"DF7F Unknown tag (1): 01",
+		"",
+	}, "\n")
+
+	if got != want {
+		t.Errorf("DumpString() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDumpString_Amounts(t *testing.T) {
+	parser := &Parser{}
+	tlvs, err := parser.Parse(hexToBytes("9F02060000000012509F03060000000000005F2A0209869F1A020076"))
+	if err != nil {
+		t.Fatalf("Parser.Parse() unexpected error = %v", err)
+	}
+
+	got := DumpString(tlvs)
+
+	want := strings.Join([]string{
+		"9F02 Amount, Authorised (Numeric) (6): 000000001250 [BRL 12.50]",
+		"9F03 Amount, Other (Numeric) (6): 000000000000 [BRL 0.00]",
+		"5F2A Transaction Currency Code (2): 0986 [986 BRL]",
+		"9F1A Terminal Country Code (2): 0076 [076 BRA]",
+		"",
+	}, "\n")
+
+	if got != want {
+		t.Errorf("DumpString() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		tag    Tag
+		format Format
+		value  string
+		want   string
+	}{
+		{name: "numeric amount", tag: 0x9F02, format: FormatN, value: "000000001250", want: "1250"},
+		{name: "numeric zero", tag: 0x9F03, format: FormatN, value: "000000000000", want: "0"},
+		{name: "compressed numeric with padding", tag: 0x5A, format: FormatCN, value: "4539578763621486FFFF", want: "4539578763621486"},
+		{name: "compressed numeric with invalid nibble", tag: 0x5A, format: FormatCN, value: "45F9", want: ""},
+		{name: "invalid BCD date", tag: 0x5F24, format: FormatN, value: "25AA31", want: ""},
+		{name: "date in previous century", tag: 0x5F25, format: FormatN, value: "991231", want: "1999-12-31"},
+		{name: "invalid calendar date", tag: 0x5F24, format: FormatN, value: "250231", want: ""},
+		{name: "non printable text", tag: 0x50, format: FormatANS, value: "0001", want: ""},
+		{name: "binary", tag: 0x82, format: FormatB, value: "1980", want: ""},
+		{name: "unknown currency", tag: 0x5F2A, format: FormatN, value: "0999", want: "999"},
+		{name: "application currency", tag: 0x9F42, format: FormatN, value: "0840", want: "840 USD"},
+		{name: "issuer country", tag: 0x5F28, format: FormatN, value: "0840", want: "840 USA"},
+		{name: "amount without currency", tag: 0x9F02, format: FormatN, value: "000000001250", want: "1250"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeValue(tt.tag, tt.format, hexToBytes(tt.value))
+			if got != tt.want {
+				t.Errorf("decodeValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDuplicateTag is returned when duplicates are found under DuplicateReject.
+var ErrDuplicateTag = errors.New("duplicate tag")
+
+// DuplicatePolicy selects which occurrence of a repeated tag is used.
+type DuplicatePolicy int
+
+const (
+	DuplicateLastWins DuplicatePolicy = iota
+	DuplicateFirstWins
+	DuplicateReject
+)
+
+func (p DuplicatePolicy) String() string {
+	switch p {
+	case DuplicateFirstWins:
+		return "first-wins"
+	case DuplicateReject:
+		return "reject"
+	default:
+		return "last-wins"
+	}
+}
+
+// Conflict reports a primitive tag that occurs more than once in the
+// tree, whether within one template or across templates. Templates holds
+// the template of each occurrence in Values, zero for top-level data
+// objects.
+type Conflict struct {
+	Tag       Tag
+	Templates []Tag
+	Values    [][]byte
+}
+
+// Conflicting reports whether the occurrences carry different values.
+func (c Conflict) Conflicting() bool {
+	for _, v := range c.Values[1:] {
+		if !bytes.Equal(v, c.Values[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Conflict) String() string {
+	kind := "identical"
+	if c.Conflicting() {
+		kind = "different"
+	}
+
+	return fmt.Sprintf("tag %s appears %d times %s with %s values", c.Tag, len(c.Values), c.where(), kind)
+}
+
+// where describes the templates the occurrences were found in.
+func (c Conflict) where() string {
+	var templates []string
+	seen := make(map[Tag]bool)
+	for _, t := range c.Templates {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if t == 0 {
+			templates = append(templates, "top level")
+		} else {
+			templates = append(templates, t.String())
+		}
+	}
+
+	switch {
+	case len(templates) > 1:
+		return "across " + strings.Join(templates, ", ")
+	case c.Templates[0] == 0:
+		return "at top level"
+	}
+	return fmt.Sprintf("in template %s", c.Templates[0])
+}
+
+// Duplicates returns the primitive tags that occur more than once anywhere
+// in the tree, in the order of their first occurrence. This is the scope
+// Lookup selects values from.
+func Duplicates(tlvs []TLV) []Conflict {
+	var seen []Conflict
+	index := make(map[Tag]int)
+	collect(tlvs, 0, &seen, index)
+
+	var result []Conflict
+	for _, c := range seen {
+		if len(c.Values) > 1 {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func collect(tlvs []TLV, template Tag, seen *[]Conflict, index map[Tag]int) {
+	for _, t := range tlvs {
+		if t.IsConstructed() {
+			collect(t.Children, t.Tag, seen, index)
+			continue
+		}
+
+		if i, ok := index[t.Tag]; ok {
+			(*seen)[i].Templates = append((*seen)[i].Templates, template)
+			(*seen)[i].Values = append((*seen)[i].Values, t.Value)
+			continue
+		}
+
+		index[t.Tag] = len(*seen)
+		*seen = append(*seen, Conflict{Tag: t.Tag, Templates: []Tag{template}, Values: [][]byte{t.Value}})
+	}
+}
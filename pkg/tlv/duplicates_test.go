@@ -0,0 +1,116 @@
+package tlv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDuplicates(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		want            []Conflict
+		wantConflicting []bool
+	}{
+		{
+			name:  "no duplicates",
+			input: "5A0845395787636214869F3403420000",
+		},
+		{
+			name:  "conflicting top level PAN",
+			input: "5A0845395787636214865A084111111111111111",
+			want: []Conflict{
+				{Tag: 0x5A, Templates: []Tag{0, 0}, Values: [][]byte{hexToBytes("4539578763621486"), hexToBytes("4111111111111111")}},
+			},
+			wantConflicting: []bool{true},
+		},
+		{
+			name:  "identical duplicate inside template",
+			input: "70105F24032512315F24032512315A024539",
+			want: []Conflict{
+				{Tag: 0x5F24, Templates: []Tag{0x70, 0x70}, Values: [][]byte{hexToBytes("251231"), hexToBytes("251231")}},
+			},
+			wantConflicting: []bool{false},
+		},
+		{
+			name:  "same tag in different records",
+			input: "70045A02453970045A024111",
+			want: []Conflict{
+				{Tag: 0x5A, Templates: []Tag{0x70, 0x70}, Values: [][]byte{hexToBytes("4539"), hexToBytes("4111")}},
+			},
+			wantConflicting: []bool{true},
+		},
+		{
+			name:  "same tag at top level and in a template",
+			input: "5A02453977045A024539",
+			want: []Conflict{
+				{Tag: 0x5A, Templates: []Tag{0, 0x77}, Values: [][]byte{hexToBytes("4539"), hexToBytes("4539")}},
+			},
+			wantConflicting: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{}
+			tlvs, err := parser.Parse(hexToBytes(tt.input))
+			if err != nil {
+				t.Fatalf("Parser.Parse() unexpected error = %v", err)
+			}
+
+			got := Duplicates(tlvs)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Duplicates() returned %d conflicts, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i].Tag != tt.want[i].Tag || !slices.Equal(got[i].Templates, tt.want[i].Templates) || len(got[i].Values) != len(tt.want[i].Values) {
+					t.Errorf("Duplicates()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+				if got[i].Conflicting() != tt.wantConflicting[i] {
+					t.Errorf("Duplicates()[%d].Conflicting() = %v, want %v", i, got[i].Conflicting(), tt.wantConflicting[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalOptions_Duplicates(t *testing.T) {
+	topLevel := "5A0845395787636214865F24032512315A084111111111111111"
+	acrossRecords := "700A5A084539578763621486" + "70105F24032512315A084111111111111111"
+
+	tests := []struct {
+		name    string
+		input   string
+		policy  DuplicatePolicy
+		wantPan string
+		wantErr error
+	}{
+		{name: "last wins", input: topLevel, policy: DuplicateLastWins, wantPan: "4111111111111111"},
+		{name: "first wins", input: topLevel, policy: DuplicateFirstWins, wantPan: "4539578763621486"},
+		{name: "reject", input: topLevel, policy: DuplicateReject, wantErr: ErrDuplicateTag},
+		{name: "last wins across records", input: acrossRecords, policy: DuplicateLastWins, wantPan: "4111111111111111"},
+		{name: "first wins across records", input: acrossRecords, policy: DuplicateFirstWins, wantPan: "4539578763621486"},
+		{name: "reject across records", input: acrossRecords, policy: DuplicateReject, wantErr: ErrDuplicateTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Pan    string    `emv:"5A,cn"`
+				Expiry time.Time `emv:"5F24,n,date"`
+			}
+
+			err := UnmarshalOptions{Duplicates: tt.policy}.Unmarshal(hexToBytes(tt.input), &got)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Pan != tt.wantPan {
+				t.Errorf("Unmarshal() Pan = %v, want %v", got.Pan, tt.wantPan)
+			}
+		})
+	}
+}
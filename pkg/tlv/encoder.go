@@ -0,0 +1,66 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Encoder serializes TLV data objects back to their BER-TLV representation.
+type Encoder struct{}
+
+func (e *Encoder) Encode(tlvs []TLV) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, t := range tlvs {
+		if err := e.encodeTLV(&buf, t); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *Encoder) encodeTLV(buf *bytes.Buffer, t TLV) error {
+	value := t.Value
+	if t.IsConstructed() && len(t.Children) > 0 {
+		encoded, err := e.Encode(t.Children)
+		if err != nil {
+			return fmt.Errorf("template %s: %w", t.TagHex(), err)
+		}
+		value = encoded
+	}
+
+	length, err := e.EncodeLength(len(value))
+	if err != nil {
+		return fmt.Errorf("tag %s: %w", t.TagHex(), err)
+	}
+
+	buf.Write(t.Tag.Bytes())
+	buf.Write(length)
+	buf.Write(value)
+
+	return nil
+}
+
+// EncodeLength returns the minimal BER encoding of a value length: short
+// form up to 127 bytes, long form (0x81-0x84) above that.
+func (e *Encoder) EncodeLength(length int) ([]byte, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("invalid negative length %d", length)
+	}
+
+	if length <= 0x7F {
+		return []byte{byte(length)}, nil
+	}
+
+	var octets []byte
+	for n := length; n > 0; n >>= 8 {
+		octets = append([]byte{byte(n)}, octets...)
+	}
+
+	if len(octets) > 4 {
+		return nil, fmt.Errorf("length %d exceeds 4 byte encoding", length)
+	}
+
+	return append([]byte{0x80 | byte(len(octets))}, octets...), nil
+}
@@ -0,0 +1,126 @@
+package tlv
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name    string
+		tlvs    []TLV
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "primitive TLVs",
+			tlvs: []TLV{
+				{Tag: 0x5A, Value: hexToBytes("4539578763621486")},
+				{Tag: 0x5F24, Value: hexToBytes("251231")},
+				{Tag: 0x9F34, Value: hexToBytes("420000")},
+			},
+			want:    "5A0845395787636214865F24032512319F3403420000",
+			wantErr: false,
+		},
+		{
+			name: "constructed template built from children",
+			tlvs: []TLV{
+				{
+					Tag: 0x70,
+					Children: []TLV{
+						{Tag: 0x5A, Value: hexToBytes("4539578763621486")},
+						{Tag: 0x5F24, Value: hexToBytes("251231")},
+					},
+				},
+			},
+			want:    "70105A0845395787636214865F2403251231",
+			wantErr: false,
+		},
+		{
+			name: "empty value",
+			tlvs: []TLV{
+				{Tag: 0x9F34},
+			},
+			want:    "9F3400",
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := &Encoder{}
+
+			got, err := encoder.Encode(tt.tlvs)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Encoder.Encode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr {
+				gotHex := strings.ToUpper(hex.EncodeToString(got))
+				if gotHex != tt.want {
+					t.Errorf("Encoder.Encode() = %v, want %v", gotHex, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoder_EncodeLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		want    string
+		wantErr bool
+	}{
+		{name: "short form", length: 8, want: "08"},
+		{name: "short form upper bound", length: 127, want: "7F"},
+		{name: "one length byte", length: 128, want: "8180"},
+		{name: "two length bytes", length: 0x0100, want: "820100"},
+		{name: "three length bytes", length: 0x010000, want: "83010000"},
+		{name: "negative length", length: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := &Encoder{}
+
+			got, err := encoder.EncodeLength(tt.length)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Encoder.EncodeLength() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr {
+				gotHex := strings.ToUpper(hex.EncodeToString(got))
+				if gotHex != tt.want {
+					t.Errorf("Encoder.EncodeLength() = %v, want %v", gotHex, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	input := "6F1A840E315041592E5359532E4444463031A5088801025F2D02656E"
+	data := hexToBytes(input)
+
+	parser := &Parser{}
+	tlvs, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Parser.Parse() unexpected error = %v", err)
+	}
+
+	encoder := &Encoder{}
+	got, err := encoder.Encode(tlvs)
+	if err != nil {
+		t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+	}
+
+	if gotHex := strings.ToUpper(hex.EncodeToString(got)); gotHex != input {
+		t.Errorf("round trip = %v, want %v", gotHex, input)
+	}
+}
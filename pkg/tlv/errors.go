@@ -0,0 +1,64 @@
+package tlv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel kinds carried by ParseError. Use errors.Is to test for them.
+var (
+	ErrEmptyBuffer       = errors.New("buffer is empty")
+	ErrTruncatedTag      = errors.New("truncated tag")
+	ErrTagTooLong        = errors.New("tag too long")
+	ErrTruncatedLength   = errors.New("truncated length")
+	ErrUnsupportedLength = errors.New("unsupported length encoding")
+	ErrNonMinimalLength  = errors.New("non-minimal length encoding")
+	ErrLengthTooLarge    = errors.New("length exceeds maximum")
+	ErrValueOverflow     = errors.New("value exceeds buffer")
+	ErrDepthExceeded     = errors.New("template nesting too deep")
+)
+
+// ParseError reports where and why TLV decoding failed. Offset is the byte
+// position in the input at which the problem was detected and Tag holds
+// the tag being decoded, which may be partial when the tag itself was
+// truncated, or zero if no tag byte had been read yet.
+type ParseError struct {
+	Offset int
+	Tag    Tag
+	Kind   error
+	Detail string
+}
+
+func newParseError(kind error, offset int, tag Tag, format string, args ...any) *ParseError {
+	return &ParseError{
+		Offset: offset,
+		Tag:    tag,
+		Kind:   kind,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%v at offset %d", e.Kind, e.Offset)
+	if e.Tag != 0 {
+		msg += fmt.Sprintf(" (tag %s)", e.Tag)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Kind
+}
+
+// at shifts the error offset by base and fills in the tag when the error
+// was raised without one, e.g. by ParseLength.
+func (e *ParseError) at(base int, tag Tag) *ParseError {
+	e.Offset += base
+	if e.Tag == 0 {
+		e.Tag = tag
+	}
+	return e
+}
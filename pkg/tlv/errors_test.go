@@ -0,0 +1,120 @@
+package tlv
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParser_Parse_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		opts       []Option
+		wantKind   error
+		wantOffset int
+		wantTag    Tag
+	}{
+		{
+			name:       "truncated multi-byte tag",
+			input:      "5A0845395787636214869F",
+			wantKind:   ErrTruncatedTag,
+			wantOffset: 11,
+			wantTag:    0x9F,
+		},
+		{
+			name:       "tag longer than four bytes",
+			input:      "DF8181818101",
+			wantKind:   ErrTagTooLong,
+			wantOffset: 4,
+			wantTag:    0xDF818181,
+		},
+		{
+			name:       "missing length",
+			input:      "5A",
+			wantKind:   ErrTruncatedLength,
+			wantOffset: 1,
+			wantTag:    0x5A,
+		},
+		{
+			name:       "truncated long form length",
+			input:      "5A0845395787636214869F3482",
+			wantKind:   ErrTruncatedLength,
+			wantOffset: 12,
+			wantTag:    0x9F34,
+		},
+		{
+			name:       "unsupported length",
+			input:      "9F3485",
+			wantKind:   ErrUnsupportedLength,
+			wantOffset: 2,
+			wantTag:    0x9F34,
+		},
+		{
+			name:       "non-minimal length in strict mode",
+			input:      "5A8108",
+			opts:       []Option{WithMode(ModeStrict)},
+			wantKind:   ErrNonMinimalLength,
+			wantOffset: 1,
+			wantTag:    0x5A,
+		},
+		{
+			name:       "length above maximum",
+			input:      "5A08",
+			opts:       []Option{WithMaxLength(4)},
+			wantKind:   ErrLengthTooLarge,
+			wantOffset: 1,
+			wantTag:    0x5A,
+		},
+		{
+			name:       "value exceeds buffer",
+			input:      "5A081234",
+			wantKind:   ErrValueOverflow,
+			wantOffset: 2,
+			wantTag:    0x5A,
+		},
+		{
+			name:       "offset inside nested template",
+			input:      "9F340300000070045A081234",
+			wantKind:   ErrValueOverflow,
+			wantOffset: 10,
+			wantTag:    0x5A,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(tt.opts...)
+
+			_, err := parser.Parse(hexToBytes(tt.input))
+
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("Parser.Parse() error = %v, want kind %v", err, tt.wantKind)
+			}
+
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parser.Parse() error %T is not a *ParseError", err)
+			}
+			if perr.Offset != tt.wantOffset {
+				t.Errorf("ParseError.Offset = %d, want %d", perr.Offset, tt.wantOffset)
+			}
+			if perr.Tag != tt.wantTag {
+				t.Errorf("ParseError.Tag = %v, want %v", perr.Tag, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestParser_ParseDOL_ErrorOffset(t *testing.T) {
+	parser := &Parser{}
+
+	_, err := parser.ParseDOL(hexToBytes("9F66049F02"))
+
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Parser.ParseDOL() error = %v, want *ParseError", err)
+	}
+	if !errors.Is(err, ErrTruncatedLength) || perr.Offset != 5 || perr.Tag != 0x9F02 {
+		t.Errorf("Parser.ParseDOL() error = %+v, want truncated length at offset 5 for tag 9F02", perr)
+	}
+}
@@ -0,0 +1,486 @@
+package tlv
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshal parses BER-TLV data and stores the values in the struct
+// pointed to by v. Fields are bound to data elements through struct tags
+// of the form `emv:"<tag>,<format>[,<option>]"`, for example:
+//
+//	Pan          string    `emv:"5A,cn"`
+//	DataValidade time.Time `emv:"5F24,n,date"`
+//
+// Supported formats are n, cn, a, an, ans and b. The date option decodes a
+// YYMMDD value into a time.Time and the month option decodes only YYMM,
+// setting the day to 1. Two-digit years are expanded with the century
+// window of DefaultCenturyPivot. Fields implementing
+// encoding.BinaryUnmarshaler receive the raw value; nil pointer fields are
+// allocated. Nested templates are searched as well; when a tag occurs more
+// than once the last occurrence wins. Use UnmarshalOptions to choose
+// another DuplicatePolicy.
+func Unmarshal(data []byte, v any) error {
+	return UnmarshalOptions{}.Unmarshal(data, v)
+}
+
+// UnmarshalTLVs is like Unmarshal for already parsed TLVs.
+func UnmarshalTLVs(tlvs []TLV, v any) error {
+	return UnmarshalOptions{}.UnmarshalTLVs(tlvs, v)
+}
+
+// UnmarshalOptions configures Unmarshal. The zero value matches Unmarshal.
+type UnmarshalOptions struct {
+	// Duplicates selects the occurrence used when a tag repeats anywhere
+	// in the tree. With DuplicateReject any repeated tag is an error.
+	Duplicates DuplicatePolicy
+	// CenturyPivot sets the century window for two-digit years: years
+	// below the pivot fall in 20YY and the others in 19YY. Zero selects
+	// DefaultCenturyPivot.
+	CenturyPivot int
+}
+
+func (o UnmarshalOptions) Unmarshal(data []byte, v any) error {
+	parser := Parser{}
+	tlvs, err := parser.Parse(data)
+	if err != nil {
+		return err
+	}
+	return o.UnmarshalTLVs(tlvs, v)
+}
+
+func (o UnmarshalOptions) UnmarshalTLVs(tlvs []TLV, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer to struct, got %T", v)
+	}
+
+	fields, err := structFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	if o.Duplicates == DuplicateReject {
+		if conflicts := Duplicates(tlvs); len(conflicts) > 0 {
+			return fmt.Errorf("%w: %s", ErrDuplicateTag, conflicts[0])
+		}
+	}
+
+	for _, f := range fields {
+		item, ok := o.Lookup(tlvs, f.tag)
+		if !ok {
+			continue
+		}
+		if err := f.decode(rv.Elem().Field(f.index), item.Value, o.pivot()); err != nil {
+			return fmt.Errorf("tag %s: %w", f.tag, err)
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the occurrence of tag that UnmarshalTLVs would decode,
+// searching nested templates and applying the duplicate policy.
+func (o UnmarshalOptions) Lookup(tlvs []TLV, tag Tag) (TLV, bool) {
+	matches := FindAll(tlvs, tag)
+	if len(matches) == 0 {
+		return TLV{}, false
+	}
+	if o.Duplicates == DuplicateFirstWins {
+		return matches[0], true
+	}
+	return matches[len(matches)-1], true
+}
+
+func (o UnmarshalOptions) pivot() int {
+	if o.CenturyPivot == 0 {
+		return DefaultCenturyPivot
+	}
+	return o.CenturyPivot
+}
+
+// Marshal encodes the tagged fields of the struct v as BER-TLV data, in
+// field declaration order. Fields holding their zero value are omitted and
+// fields implementing encoding.BinaryMarshaler encode themselves.
+func Marshal(v any) ([]byte, error) {
+	tlvs, err := MarshalTLVs(v)
+	if err != nil {
+		return nil, err
+	}
+	encoder := Encoder{}
+	return encoder.Encode(tlvs)
+}
+
+// MarshalTLVs is like Marshal but returns the TLVs instead of encoding them.
+func MarshalTLVs(v any) ([]TLV, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("marshal source must be a struct, got %T", v)
+	}
+
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TLV, 0, len(fields))
+	for _, f := range fields {
+		fv := rv.Field(f.index)
+		if fv.IsZero() {
+			continue
+		}
+
+		value, err := f.encode(fv)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", f.tag, err)
+		}
+
+		result = append(result, TLV{Tag: f.tag, Len: len(value), Value: value})
+	}
+
+	return result, nil
+}
+
+type emvField struct {
+	index  int
+	tag    Tag
+	format Format
+	option string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func structFields(t reflect.Type) ([]emvField, error) {
+	fields := make([]emvField, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		spec, ok := sf.Tag.Lookup("emv")
+		if !ok || spec == "-" || !sf.IsExported() {
+			continue
+		}
+
+		parts := strings.Split(spec, ",")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("field %s: invalid emv tag %q", sf.Name, spec)
+		}
+
+		format, err := parseFormat(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+
+		tag, err := ParseTagHex(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+
+		f := emvField{index: i, tag: tag, format: format}
+		if len(parts) == 3 {
+			f.option = parts[2]
+			if f.option != "date" && f.option != "month" {
+				return nil, fmt.Errorf("field %s: unknown emv option %q", sf.Name, f.option)
+			}
+			if sf.Type != timeType {
+				return nil, fmt.Errorf("field %s: option %q requires time.Time", sf.Name, f.option)
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+func parseFormat(s string) (Format, error) {
+	switch s {
+	case "b":
+		return FormatB, nil
+	case "n":
+		return FormatN, nil
+	case "cn":
+		return FormatCN, nil
+	case "a":
+		return FormatA, nil
+	case "an":
+		return FormatAN, nil
+	case "ans":
+		return FormatANS, nil
+	}
+	return 0, fmt.Errorf("unknown emv format %q", s)
+}
+
+func (f emvField) decode(dst reflect.Value, value []byte, pivot int) error {
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	if f.option != "" {
+		date, err := decodeDate(value, f.option == "month", pivot)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(date))
+		return nil
+	}
+
+	if u, ok := dst.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+		return u.UnmarshalBinary(append([]byte(nil), value...))
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		s, err := f.decodeString(value)
+		if err != nil {
+			return err
+		}
+		dst.SetString(s)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := f.decodeUint(value)
+		if err != nil {
+			return err
+		}
+		if n > math.MaxInt64 || dst.OverflowInt(int64(n)) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetInt(int64(n))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := f.decodeUint(value)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowUint(n) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetUint(n)
+
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported field type %s", dst.Type())
+		}
+		dst.SetBytes(append([]byte(nil), value...))
+
+	default:
+		return fmt.Errorf("unsupported field type %s", dst.Type())
+	}
+
+	return nil
+}
+
+func (f emvField) decodeString(value []byte) (string, error) {
+	switch f.format {
+	case FormatN:
+		digits, ok := bcdDigits(value)
+		if !ok {
+			return "", fmt.Errorf("invalid BCD value %X", value)
+		}
+		return digits, nil
+	case FormatCN:
+		digits, ok := cnDigits(value)
+		if !ok {
+			return "", fmt.Errorf("invalid compressed numeric value %X", value)
+		}
+		return digits, nil
+	case FormatA, FormatAN, FormatANS:
+		return string(value), nil
+	default:
+		return strings.ToUpper(hex.EncodeToString(value)), nil
+	}
+}
+
+func (f emvField) decodeUint(value []byte) (uint64, error) {
+	switch f.format {
+	case FormatN, FormatCN:
+		s, err := f.decodeString(value)
+		if err != nil {
+			return 0, err
+		}
+		if s == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid numeric value %s: %w", s, err)
+		}
+		return n, nil
+	case FormatB:
+		if len(value) > 8 {
+			return 0, fmt.Errorf("binary value of %d bytes overflows uint64", len(value))
+		}
+		var n uint64
+		for _, b := range value {
+			n = n<<8 | uint64(b)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("format %s cannot be decoded as an integer", f.format)
+}
+
+func (f emvField) encode(src reflect.Value) ([]byte, error) {
+	if f.option != "" {
+		return encodeDate(src.Interface().(time.Time), f.fixedLen())
+	}
+
+	if m, ok := src.Interface().(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+	if src.Kind() == reflect.Pointer {
+		src = src.Elem()
+	}
+
+	switch src.Kind() {
+	case reflect.String:
+		return f.encodeString(src.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if src.Int() < 0 {
+			return nil, fmt.Errorf("cannot encode negative value %d", src.Int())
+		}
+		return f.encodeUint(uint64(src.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return f.encodeUint(src.Uint())
+	case reflect.Slice:
+		if src.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("unsupported field type %s", src.Type())
+		}
+		return append([]byte(nil), src.Bytes()...), nil
+	}
+
+	return nil, fmt.Errorf("unsupported field type %s", src.Type())
+}
+
+func (f emvField) encodeString(s string) ([]byte, error) {
+	switch f.format {
+	case FormatN:
+		return encodeBCD(s, f.fixedLen(), false)
+	case FormatCN:
+		return encodeBCD(s, f.fixedLen(), true)
+	case FormatA, FormatAN, FormatANS:
+		return []byte(s), nil
+	default:
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value %q: %w", s, err)
+		}
+		return b, nil
+	}
+}
+
+func (f emvField) encodeUint(n uint64) ([]byte, error) {
+	switch f.format {
+	case FormatN, FormatCN:
+		return f.encodeString(strconv.FormatUint(n, 10))
+	case FormatB:
+		size := f.fixedLen()
+		if size == 0 {
+			for m := n; m > 0; m >>= 8 {
+				size++
+			}
+		}
+		out := make([]byte, size)
+		for i := size - 1; i >= 0; i-- {
+			out[i] = byte(n)
+			n >>= 8
+		}
+		if n != 0 {
+			return nil, fmt.Errorf("value does not fit in %d bytes", size)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("format %s cannot be encoded from an integer", f.format)
+}
+
+// fixedLen returns the dictionary length of the tag when it is fixed, or 0.
+func (f emvField) fixedLen() int {
+	if info, ok := LookupTag(f.tag); ok && info.MinLen == info.MaxLen {
+		return info.MinLen
+	}
+	return 0
+}
+
+// encodeBCD packs decimal digits two per byte. Numeric values are right
+// justified with leading zeros, compressed numeric values are left
+// justified with trailing 'F's. A size of 0 uses the minimal length.
+func encodeBCD(digits string, size int, compressed bool) ([]byte, error) {
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid digit %q in %q", c, digits)
+		}
+	}
+
+	if size == 0 {
+		size = (len(digits) + 1) / 2
+	}
+	if len(digits) > size*2 {
+		return nil, fmt.Errorf("%d digits do not fit in %d bytes", len(digits), size)
+	}
+
+	pad := strings.Repeat("0", size*2-len(digits))
+	if compressed {
+		digits += strings.Repeat("F", len(pad))
+	} else {
+		digits = pad + digits
+	}
+
+	return hex.DecodeString(digits)
+}
+
+// DefaultCenturyPivot is the century window used for two-digit years
+// unless configured otherwise: 00-49 are read as 2000-2049 and 50-99 as
+// 1950-1999, as recommended by EMV Book 4.
+const DefaultCenturyPivot = 50
+
+// ExpandYear converts the two-digit year yy to a four-digit year. Years
+// below pivot fall in the 21st century and the others in the 20th.
+func ExpandYear(yy, pivot int) int {
+	if yy < pivot {
+		return 2000 + yy
+	}
+	return 1900 + yy
+}
+
+func decodeDate(value []byte, monthOnly bool, pivot int) (time.Time, error) {
+	digits, ok := bcdDigits(value)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid BCD date %X", value)
+	}
+
+	if monthOnly {
+		if len(digits) < 4 {
+			return time.Time{}, fmt.Errorf("invalid date %s: expected YYMM", digits)
+		}
+		digits = digits[:4] + "01"
+	} else if len(digits) != 6 {
+		return time.Time{}, fmt.Errorf("invalid date %s: expected YYMMDD", digits)
+	}
+
+	yy, _ := strconv.Atoi(digits[:2])
+	year := strconv.Itoa(ExpandYear(yy, pivot))
+	date, err := time.Parse("20060102", year+digits[2:])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %s: %w", digits, err)
+	}
+
+	return date, nil
+}
+
+func encodeDate(date time.Time, size int) ([]byte, error) {
+	layout := "060102"
+	if size == 2 {
+		layout = "0601"
+	}
+	return hex.DecodeString(date.Format(layout))
+}
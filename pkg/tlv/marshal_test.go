@@ -0,0 +1,189 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRecord struct {
+	Pan        string    `emv:"5A,cn"`
+	Expiry     time.Time `emv:"5F24,n,date"`
+	Label      string    `emv:"50,ans"`
+	Amount     uint64    `emv:"9F02,n"`
+	Currency   int       `emv:"5F2A,n"`
+	ATC        uint16    `emv:"9F36,b"`
+	CVMResults string    `emv:"9F34,b"`
+	IssuerData []byte    `emv:"9F10,b"`
+	Note       string
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := hexToBytes("70165A0845395787636214FF5F24032512315004564953419F02060000000012505F2A0209869F360200129F34031F00029F1003010203")
+
+	var got testRecord
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error = %v", err)
+	}
+
+	want := testRecord{
+		Pan:        "45395787636214",
+		Expiry:     time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		Label:      "VISA",
+		Amount:     1250,
+		Currency:   986,
+		ATC:        0x12,
+		CVMResults: "1F0002",
+		IssuerData: hexToBytes("010203"),
+	}
+
+	if got.Pan != want.Pan {
+		t.Errorf("Unmarshal() Pan = %v, want %v", got.Pan, want.Pan)
+	}
+	if !got.Expiry.Equal(want.Expiry) {
+		t.Errorf("Unmarshal() Expiry = %v, want %v", got.Expiry, want.Expiry)
+	}
+	if got.Label != want.Label {
+		t.Errorf("Unmarshal() Label = %v, want %v", got.Label, want.Label)
+	}
+	if got.Amount != want.Amount {
+		t.Errorf("Unmarshal() Amount = %v, want %v", got.Amount, want.Amount)
+	}
+	if got.Currency != want.Currency {
+		t.Errorf("Unmarshal() Currency = %v, want %v", got.Currency, want.Currency)
+	}
+	if got.ATC != want.ATC {
+		t.Errorf("Unmarshal() ATC = %v, want %v", got.ATC, want.ATC)
+	}
+	if got.CVMResults != want.CVMResults {
+		t.Errorf("Unmarshal() CVMResults = %v, want %v", got.CVMResults, want.CVMResults)
+	}
+	if !bytes.Equal(got.IssuerData, want.IssuerData) {
+		t.Errorf("Unmarshal() IssuerData = %X, want %X", got.IssuerData, want.IssuerData)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		target any
+	}{
+		{name: "invalid BCD date", input: "5F240325AA31", target: &testRecord{}},
+		{name: "invalid calendar date", input: "5F2403251332", target: &testRecord{}},
+		{name: "day past end of month", input: "5F2403250231", target: &testRecord{}},
+		{name: "day zero", input: "5F2403251200", target: &testRecord{}},
+		{name: "truncated date", input: "5F24022512", target: &testRecord{}},
+		{name: "invalid compressed numeric", input: "5A024F12", target: &testRecord{}},
+		{name: "integer overflow", input: "9F3603010000", target: &testRecord{}},
+		{name: "malformed TLV", input: "5A08", target: &testRecord{}},
+		{name: "non pointer target", input: "", target: testRecord{}},
+		{name: "invalid struct tag", input: "", target: &struct {
+			Pan string `emv:"5A"`
+		}{}},
+		{name: "unknown option", input: "", target: &struct {
+			Expiry time.Time `emv:"5F24,n,week"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(hexToBytes(tt.input), tt.target); err == nil {
+				t.Errorf("Unmarshal() expected error")
+			}
+		})
+	}
+}
+
+func TestUnmarshal_MonthOption(t *testing.T) {
+	var got struct {
+		Expiry time.Time `emv:"5F24,n,month"`
+	}
+
+	if err := Unmarshal(hexToBytes("5F2403251231"), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error = %v", err)
+	}
+
+	want := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Expiry.Equal(want) {
+		t.Errorf("Unmarshal() Expiry = %v, want %v", got.Expiry, want)
+	}
+}
+
+func TestUnmarshalOptions_CenturyPivot(t *testing.T) {
+	tests := []struct {
+		name  string
+		pivot int
+		input string
+		want  time.Time
+	}{
+		{name: "default window current century", input: "5F2403491231", want: time.Date(2049, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "default window previous century", input: "5F2403500101", want: time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "custom pivot", pivot: 80, input: "5F2403700630", want: time.Date(2070, 6, 30, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", input: "5F2403280229", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testRecord
+			opts := UnmarshalOptions{CenturyPivot: tt.pivot}
+			if err := opts.Unmarshal(hexToBytes(tt.input), &got); err != nil {
+				t.Fatalf("UnmarshalOptions.Unmarshal() unexpected error = %v", err)
+			}
+			if !got.Expiry.Equal(tt.want) {
+				t.Errorf("UnmarshalOptions.Unmarshal() Expiry = %v, want %v", got.Expiry, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	record := testRecord{
+		Pan:        "453957876362148",
+		Expiry:     time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		Amount:     1250,
+		Currency:   986,
+		ATC:        0x12,
+		CVMResults: "1F0002",
+	}
+
+	got, err := Marshal(record)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error = %v", err)
+	}
+
+	want := "5A08453957876362148F5F24032512319F02060000000012505F2A0209869F360200129F34031F0002"
+	if gotHex := strings.ToUpper(hex.EncodeToString(got)); gotHex != want {
+		t.Errorf("Marshal() = %v, want %v", gotHex, want)
+	}
+
+	var decoded testRecord
+	if err := Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("Unmarshal() unexpected error = %v", err)
+	}
+	if decoded.Pan != record.Pan || decoded.Amount != record.Amount || !decoded.Expiry.Equal(record.Expiry) {
+		t.Errorf("round trip = %+v, want %+v", decoded, record)
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source any
+	}{
+		{name: "not a struct", source: "5A"},
+		{name: "non numeric digits", source: testRecord{Pan: "4539X"}},
+		{name: "numeric too long", source: testRecord{Currency: 12345}},
+		{name: "invalid hex", source: testRecord{CVMResults: "XYZ"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.source); err == nil {
+				t.Errorf("Marshal() expected error")
+			}
+		})
+	}
+}
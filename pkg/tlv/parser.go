@@ -2,68 +2,168 @@ package tlv
 
 import (
 	"encoding/hex"
-	"fmt"
+	"math"
 	"strings"
 )
 
-// Parser parses EMV TLV encoded data according to EMV 4.3 Book 3
-type Parser struct{}
+// Parser parses EMV TLV encoded data according to EMV 4.3 Book 3.
+// The zero value parses in ModeDefault without length or depth limits.
+type Parser struct {
+	mode      Mode
+	maxLength int
+	maxDepth  int
+}
+
+// Mode controls how tolerant the parser is of non-canonical encodings.
+type Mode int
+
+const (
+	// ModeDefault accepts any well-formed length encoding.
+	ModeDefault Mode = iota
+	// ModeStrict rejects lengths that are not minimally encoded.
+	ModeStrict
+	// ModeLenient skips 0x00 and 0xFF padding bytes between data objects,
+	// as allowed by EMV Book 3 Annex B.
+	ModeLenient
+)
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithMode selects the parse mode.
+func WithMode(mode Mode) Option {
+	return func(p *Parser) {
+		p.mode = mode
+	}
+}
+
+// WithMaxLength rejects data objects whose declared length exceeds max.
+// A value of zero disables the check.
+func WithMaxLength(max int) Option {
+	return func(p *Parser) {
+		p.maxLength = max
+	}
+}
+
+// WithMaxDepth limits how deeply templates may be nested, counting
+// top-level data objects as depth 1. A value of zero disables the check.
+func WithMaxDepth(max int) Option {
+	return func(p *Parser) {
+		p.maxDepth = max
+	}
+}
 
-// TLV represents a Tag-Length-Value structure.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// TLV represents a Tag-Length-Value structure. Constructed data objects
+// (templates) also carry their decoded nested objects in Children.
 type TLV struct {
-	Tag   []byte
-	Len   int
-	Value []byte
+	Tag      Tag
+	Len      int
+	Value    []byte
+	Children []TLV
 }
 
 const (
 	maskMultiByteTag = 0x1F
 	maskContinuation = 0x80
+	maskConstructed  = 0x20
+	maskLongForm     = 0x80
+
+	maxLengthOctets = 4
 )
 
+// Parse decodes all TLV data objects in data, recursing into templates.
+// Decoding failures are reported as *ParseError.
 func (p *Parser) Parse(data []byte) ([]TLV, error) {
+	tlvs, err := p.parse(data, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	return tlvs, nil
+}
+
+func (p *Parser) parse(data []byte, base, depth int) ([]TLV, *ParseError) {
 
 	result := make([]TLV, 0, 3)
 	pos := 0
 
 	for pos < len(data) {
 
-		tag, usedTag, err := p.ParseTag(data[pos:])
+		if p.mode == ModeLenient && isPadding(data[pos]) {
+			pos++
+			continue
+		}
+
+		tag, usedTag, err := p.parseTag(data[pos:])
 		if err != nil {
-			return nil, err
+			return nil, err.at(base+pos, 0)
 		}
 		pos += usedTag
 
-		L, usedLen, err := p.ParseLength(data[pos:])
+		L, usedLen, err := p.parseLength(data[pos:])
 		if err != nil {
-			return nil, err
+			return nil, err.at(base+pos, tag)
 		}
 		pos += usedLen
 
-		if pos+L > len(data) {
-			return nil, fmt.Errorf("value length %d exceeds buffer at position %d (total: %d)", L, pos, len(data))
+		if L > len(data)-pos {
+			return nil, newParseError(ErrValueOverflow, base+pos, tag, "value length %d but only %d bytes left", L, len(data)-pos)
 		}
 
 		value := data[pos : pos+L]
 		pos += L
 
-		result = append(result, TLV{
+		item := TLV{
 			Tag:   tag,
 			Len:   L,
 			Value: value,
-		})
+		}
+
+		if item.IsConstructed() {
+			children, err := p.parseChildren(value, base+pos-L, tag, depth)
+			if err != nil {
+				return nil, err
+			}
+			item.Children = children
+		}
+
+		result = append(result, item)
 	}
 
 	return result, nil
 }
 
-func (p *Parser) ParseTag(data []byte) ([]byte, int, error) {
+func (p *Parser) parseChildren(value []byte, base int, tag Tag, depth int) ([]TLV, *ParseError) {
+	if p.maxDepth > 0 && depth >= p.maxDepth {
+		return nil, newParseError(ErrDepthExceeded, base, tag, "templates nested deeper than %d levels", p.maxDepth)
+	}
+	return p.parse(value, base, depth+1)
+}
+
+// ParseTag decodes the tag at the start of data and returns it with the
+// number of bytes consumed.
+func (p *Parser) ParseTag(data []byte) (Tag, int, error) {
+	tag, used, err := p.parseTag(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return tag, used, nil
+}
+
+func (p *Parser) parseTag(data []byte) (Tag, int, *ParseError) {
 	if len(data) == 0 {
-		return nil, 0, fmt.Errorf("buffer is empty")
+		return 0, 0, newParseError(ErrEmptyBuffer, 0, 0, "no tag to parse")
 	}
 
 	b1 := data[0]
-	tag := []byte{b1}
+	tag := Tag(b1)
 	pos := 1
 
 	if (b1 & maskMultiByteTag) != maskMultiByteTag {
@@ -72,11 +172,14 @@ func (p *Parser) ParseTag(data []byte) ([]byte, int, error) {
 
 	for {
 		if pos >= len(data) {
-			return nil, 0, fmt.Errorf("incomplete tag: unexpected end of data at position %d", pos)
+			return 0, 0, newParseError(ErrTruncatedTag, pos, tag, "unexpected end of data")
+		}
+		if pos >= maxTagBytes {
+			return 0, 0, newParseError(ErrTagTooLong, pos, tag, "tags are limited to %d bytes", maxTagBytes)
 		}
 
 		b := data[pos]
-		tag = append(tag, b)
+		tag = tag<<8 | Tag(b)
 		pos++
 
 		// Bit 8 = 0 indicates last byte of multi-byte tag
@@ -88,39 +191,91 @@ func (p *Parser) ParseTag(data []byte) ([]byte, int, error) {
 	return tag, pos, nil
 }
 
+// ParseLength decodes the BER length at the start of data and returns it
+// with the number of bytes consumed.
 func (p *Parser) ParseLength(data []byte) (int, int, error) {
+	length, used, err := p.parseLength(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return length, used, nil
+}
+
+func (p *Parser) parseLength(data []byte) (int, int, *ParseError) {
 	if len(data) < 1 {
-		return 0, 0, fmt.Errorf("cannot parse length from empty buffer")
+		return 0, 0, newParseError(ErrTruncatedLength, 0, 0, "no length to parse")
 	}
 
 	firstByte := data[0]
+	length, used := int(firstByte), 1
 
-	if firstByte <= 0x7F {
-		return int(firstByte), 1, nil
-	}
+	if firstByte > 0x7F {
+		octets := int(firstByte &^ maskLongForm)
+		if octets == 0 || octets > maxLengthOctets {
+			return 0, 0, newParseError(ErrUnsupportedLength, 0, 0, "first length byte 0x%02X", firstByte)
+		}
 
-	if firstByte == 0x81 {
-		if len(data) < 2 {
-			return 0, 0, fmt.Errorf("incomplete length encoding: expected 2 bytes, got %d", len(data))
+		used = octets + 1
+		if len(data) < used {
+			return 0, 0, newParseError(ErrTruncatedLength, 0, 0, "expected %d bytes, got %d", used, len(data))
 		}
-		return int(data[1]), 2, nil
-	}
 
-	if firstByte == 0x82 {
-		if len(data) < 3 {
-			return 0, 0, fmt.Errorf("incomplete length encoding: expected 3 bytes, got %d", len(data))
+		// Accumulate in 64 bits and reject lengths that do not fit in an
+		// int32, so that the result is non-negative on 32-bit platforms.
+		var n uint64
+		for _, b := range data[1:used] {
+			n = n<<8 | uint64(b)
+		}
+		if n > math.MaxInt32 {
+			return 0, 0, newParseError(ErrLengthTooLarge, 0, 0, "%d exceeds maximum of %d", n, math.MaxInt32)
+		}
+		length = int(n)
+
+		if p.mode == ModeStrict && !isMinimalLength(length, octets) {
+			return 0, 0, newParseError(ErrNonMinimalLength, 0, 0, "%d encoded in %d bytes", length, used)
 		}
-		length := int(data[1])<<8 | int(data[2])
-		return length, 3, nil
 	}
 
-	return 0, 0, fmt.Errorf("unsupported length encoding: 0x%02X", firstByte)
+	if p.maxLength > 0 && length > p.maxLength {
+		return 0, 0, newParseError(ErrLengthTooLarge, 0, 0, "%d exceeds maximum of %d", length, p.maxLength)
+	}
+
+	return length, used, nil
+}
+
+func isMinimalLength(length, octets int) bool {
+	if length <= 0x7F {
+		return false
+	}
+	return length >= 1<<(8*(octets-1))
+}
+
+func isPadding(b byte) bool {
+	return b == 0x00 || b == 0xFF
 }
 
 func (t TLV) TagHex() string {
-	return strings.ToUpper(hex.EncodeToString(t.Tag))
+	return t.Tag.String()
 }
 
 func (t TLV) ValueHex() string {
 	return strings.ToUpper(hex.EncodeToString(t.Value))
 }
+
+// IsConstructed reports whether the TLV is a template holding nested data objects.
+func (t TLV) IsConstructed() bool {
+	return t.Tag.IsConstructed()
+}
+
+// Flatten walks a parsed TLV tree depth-first and returns every data
+// object, templates included, as a single slice.
+func Flatten(tlvs []TLV) []TLV {
+	result := make([]TLV, 0, len(tlvs))
+	for _, t := range tlvs {
+		result = append(result, t)
+		if len(t.Children) > 0 {
+			result = append(result, Flatten(t.Children)...)
+		}
+	}
+	return result
+}
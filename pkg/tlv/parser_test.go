@@ -3,7 +3,7 @@ package tlv
 import (
 	"bytes"
 	"encoding/hex"
-	"strings"
+	"math"
 	"testing"
 )
 
@@ -19,9 +19,9 @@ func TestParser_Parse(t *testing.T) {
 			name:  "parse valid TLV data with PAN, expiry date and CVM",
 			input: "5A0812345678901234565F2404251200009F340442000000",
 			want: []TLV{
-				{Tag: hexToBytes("5A"), Len: 8, Value: hexToBytes("1234567890123456")},
-				{Tag: hexToBytes("5F24"), Len: 4, Value: hexToBytes("25120000")},
-				{Tag: hexToBytes("9F34"), Len: 4, Value: hexToBytes("42000000")},
+				{Tag: 0x5A, Len: 8, Value: hexToBytes("1234567890123456")},
+				{Tag: 0x5F24, Len: 4, Value: hexToBytes("25120000")},
+				{Tag: 0x9F34, Len: 4, Value: hexToBytes("42000000")},
 			},
 			wantErr: false,
 		},
@@ -29,7 +29,7 @@ func TestParser_Parse(t *testing.T) {
 			name:  "parse single TLV",
 			input: "5A084539578763621486",
 			want: []TLV{
-				{Tag: hexToBytes("5A"), Len: 8, Value: hexToBytes("4539578763621486")},
+				{Tag: 0x5A, Len: 8, Value: hexToBytes("4539578763621486")},
 			},
 			wantErr: false,
 		},
@@ -45,6 +45,12 @@ func TestParser_Parse(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "length far beyond the remaining data",
+			input:   "5A847FFFFFFF4539",
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "incomplete TLV - missing length and value",
 			input:   "5A",
@@ -72,8 +78,8 @@ func TestParser_Parse(t *testing.T) {
 				}
 
 				for i := range got {
-					if !bytes.Equal(got[i].Tag, tt.want[i].Tag) {
-						t.Errorf("Parser.Parse() TLV[%d].Tag = %X, want %X", i, got[i].Tag, tt.want[i].Tag)
+					if got[i].Tag != tt.want[i].Tag {
+						t.Errorf("Parser.Parse() TLV[%d].Tag = %v, want %v", i, got[i].Tag, tt.want[i].Tag)
 					}
 					if got[i].Len != tt.want[i].Len {
 						t.Errorf("Parser.Parse() TLV[%d].Len = %v, want %v", i, got[i].Len, tt.want[i].Len)
@@ -87,6 +93,117 @@ func TestParser_Parse(t *testing.T) {
 	}
 }
 
+func TestParser_Parse_Constructed(t *testing.T) {
+	parser := &Parser{}
+	// 70 (READ RECORD template) wrapping PAN and expiry, followed by a primitive CVM
+	data, _ := hex.DecodeString("70105A0845395787636214865F24032512319F3403420000")
+
+	got, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Parser.Parse() unexpected error = %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Parser.Parse() returned %d TLVs, want 2", len(got))
+	}
+
+	template := got[0]
+	if !template.IsConstructed() {
+		t.Errorf("TLV 70 IsConstructed() = false, want true")
+	}
+	if len(template.Children) != 2 {
+		t.Fatalf("TLV 70 has %d children, want 2", len(template.Children))
+	}
+	if template.Children[0].TagHex() != "5A" || template.Children[0].ValueHex() != "4539578763621486" {
+		t.Errorf("TLV 70 child[0] = %s/%s, want 5A/4539578763621486", template.Children[0].TagHex(), template.Children[0].ValueHex())
+	}
+	if template.Children[1].TagHex() != "5F24" || template.Children[1].ValueHex() != "251231" {
+		t.Errorf("TLV 70 child[1] = %s/%s, want 5F24/251231", template.Children[1].TagHex(), template.Children[1].ValueHex())
+	}
+
+	if got[1].IsConstructed() || len(got[1].Children) != 0 {
+		t.Errorf("TLV 9F34 should be primitive without children")
+	}
+
+	flat := Flatten(got)
+	wantTags := []string{"70", "5A", "5F24", "9F34"}
+	if len(flat) != len(wantTags) {
+		t.Fatalf("Flatten() returned %d TLVs, want %d", len(flat), len(wantTags))
+	}
+	for i, tag := range wantTags {
+		if flat[i].TagHex() != tag {
+			t.Errorf("Flatten()[%d].Tag = %s, want %s", i, flat[i].TagHex(), tag)
+		}
+	}
+}
+
+func TestParser_Parse_ConstructedInvalidChild(t *testing.T) {
+	parser := &Parser{}
+	// 77 template whose child claims 8 bytes but only has 2
+	data, _ := hex.DecodeString("77045A081234")
+
+	if _, err := parser.Parse(data); err == nil {
+		t.Errorf("Parser.Parse() expected error for malformed template child")
+	}
+}
+
+func TestParser_Parse_Modes(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		opts      []Option
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:    "padding rejected by default",
+			input:   "5A084539578763621486FF",
+			wantErr: true,
+		},
+		{
+			name:      "padding skipped in lenient mode",
+			input:     "005A084539578763621486FF9F340342000000",
+			opts:      []Option{WithMode(ModeLenient)},
+			wantCount: 2,
+		},
+		{
+			name:      "padding inside template skipped in lenient mode",
+			input:     "700C5A0845395787636214860000",
+			opts:      []Option{WithMode(ModeLenient)},
+			wantCount: 1,
+		},
+		{
+			name:    "non-minimal length rejected in strict mode",
+			input:   "5A81084539578763621486",
+			opts:    []Option{WithMode(ModeStrict)},
+			wantErr: true,
+		},
+		{
+			name:      "non-minimal length accepted by default",
+			input:     "5A81084539578763621486",
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(tt.opts...)
+			data, _ := hex.DecodeString(tt.input)
+
+			got, err := parser.Parse(data)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && len(got) != tt.wantCount {
+				t.Errorf("Parser.Parse() returned %d TLVs, want %d", len(got), tt.wantCount)
+			}
+		})
+	}
+}
+
 func TestParser_ParseTag(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -116,6 +233,18 @@ func TestParser_ParseTag(t *testing.T) {
 			wantUsed: 2,
 			wantErr:  false,
 		},
+		{
+			name:     "four byte tag",
+			input:    "DF81810101",
+			wantTag:  "DF818101",
+			wantUsed: 4,
+			wantErr:  false,
+		},
+		{
+			name:    "tag longer than four bytes",
+			input:   "DF818181010101",
+			wantErr: true,
+		},
 		{
 			name:    "empty buffer",
 			input:   "",
@@ -136,7 +265,7 @@ func TestParser_ParseTag(t *testing.T) {
 			}
 
 			if !tt.wantErr {
-				gotTag := strings.ToUpper(hex.EncodeToString(tag))
+				gotTag := tag.String()
 				if gotTag != tt.wantTag {
 					t.Errorf("Parser.ParseTag() tag = %v, want %v", gotTag, tt.wantTag)
 				}
@@ -152,6 +281,7 @@ func TestParser_ParseLength(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
+		opts     []Option
 		wantLen  int
 		wantUsed int
 		wantErr  bool
@@ -170,6 +300,94 @@ func TestParser_ParseLength(t *testing.T) {
 			wantUsed: 1,
 			wantErr:  false,
 		},
+		{
+			name:     "long form one byte",
+			input:    "8180",
+			wantLen:  128,
+			wantUsed: 2,
+			wantErr:  false,
+		},
+		{
+			name:     "long form two bytes",
+			input:    "820100",
+			wantLen:  256,
+			wantUsed: 3,
+			wantErr:  false,
+		},
+		{
+			name:     "long form three bytes",
+			input:    "83010000",
+			wantLen:  65536,
+			wantUsed: 4,
+			wantErr:  false,
+		},
+		{
+			name:     "long form four bytes",
+			input:    "8401000000",
+			wantLen:  16777216,
+			wantUsed: 5,
+			wantErr:  false,
+		},
+		{
+			name:     "non-minimal encoding accepted by default",
+			input:    "8105",
+			wantLen:  5,
+			wantUsed: 2,
+			wantErr:  false,
+		},
+		{
+			name:    "non-minimal encoding rejected in strict mode",
+			input:   "8105",
+			opts:    []Option{WithMode(ModeStrict)},
+			wantErr: true,
+		},
+		{
+			name:    "non-minimal two byte encoding rejected in strict mode",
+			input:   "820080",
+			opts:    []Option{WithMode(ModeStrict)},
+			wantErr: true,
+		},
+		{
+			name:     "minimal encoding accepted in strict mode",
+			input:    "8180",
+			opts:     []Option{WithMode(ModeStrict)},
+			wantLen:  128,
+			wantUsed: 2,
+			wantErr:  false,
+		},
+		{
+			name:    "length above configured maximum",
+			input:   "820100",
+			opts:    []Option{WithMaxLength(255)},
+			wantErr: true,
+		},
+		{
+			name:    "indefinite length",
+			input:   "80",
+			wantErr: true,
+		},
+		{
+			name:    "four byte length above int32",
+			input:   "8480000000",
+			wantErr: true,
+		},
+		{
+			name:     "largest four byte length",
+			input:    "847FFFFFFF",
+			wantLen:  math.MaxInt32,
+			wantUsed: 5,
+			wantErr:  false,
+		},
+		{
+			name:    "more than four length bytes",
+			input:   "850100000000",
+			wantErr: true,
+		},
+		{
+			name:    "truncated long form",
+			input:   "8301",
+			wantErr: true,
+		},
 		{
 			name:    "empty buffer",
 			input:   "",
@@ -179,7 +397,7 @@ func TestParser_ParseLength(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parser := &Parser{}
+			parser := NewParser(tt.opts...)
 			data, _ := hex.DecodeString(tt.input)
 
 			length, used, err := parser.ParseLength(data)
@@ -0,0 +1,92 @@
+package tlv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when a path segment has no match.
+var ErrNotFound = errors.New("tag not found")
+
+// Find returns the first data object with the given tag, searching the
+// tree depth-first.
+func Find(tlvs []TLV, tag Tag) (TLV, bool) {
+	for _, t := range tlvs {
+		if t.Tag == tag {
+			return t, true
+		}
+		if found, ok := Find(t.Children, tag); ok {
+			return found, true
+		}
+	}
+	return TLV{}, false
+}
+
+// FindAll returns every data object with the given tag, in depth-first order.
+func FindAll(tlvs []TLV, tag Tag) []TLV {
+	var result []TLV
+	for _, t := range tlvs {
+		if t.Tag == tag {
+			result = append(result, t)
+		}
+		result = append(result, FindAll(t.Children, tag)...)
+	}
+	return result
+}
+
+// Get follows a slash separated path of tags, such as "6F/A5/BF0C/61/4F",
+// where each segment must be a direct child of the previous one. When a
+// template repeats, every occurrence is tried in order.
+func Get(tlvs []TLV, path string) (TLV, error) {
+	segments := strings.Split(path, "/")
+	tags := make([]Tag, len(segments))
+	for i, segment := range segments {
+		tag, err := ParseTagHex(segment)
+		if err != nil {
+			return TLV{}, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		tags[i] = tag
+	}
+
+	found, depth := get(tlvs, tags)
+	if depth < len(tags) {
+		return TLV{}, fmt.Errorf("%w: %s in path %s", ErrNotFound, tags[depth], path)
+	}
+	return found, nil
+}
+
+// get returns the match for tags and, on failure, how many segments of
+// the path could be matched at best.
+func get(tlvs []TLV, tags []Tag) (TLV, int) {
+	best := 0
+	for _, t := range tlvs {
+		if t.Tag != tags[0] {
+			continue
+		}
+		if len(tags) == 1 {
+			return t, 1
+		}
+		found, depth := get(t.Children, tags[1:])
+		if depth == len(tags)-1 {
+			return found, len(tags)
+		}
+		best = max(best, depth+1)
+	}
+	return TLV{}, best
+}
+
+// Find searches the children of t. See Find.
+func (t TLV) Find(tag Tag) (TLV, bool) {
+	return Find(t.Children, tag)
+}
+
+// FindAll searches the children of t. See FindAll.
+func (t TLV) FindAll(tag Tag) []TLV {
+	return FindAll(t.Children, tag)
+}
+
+// Get resolves path relative to the children of t. See Get.
+func (t TLV) Get(path string) (TLV, error) {
+	return Get(t.Children, path)
+}
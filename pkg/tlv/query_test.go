@@ -0,0 +1,110 @@
+package tlv
+
+import (
+	"errors"
+	"testing"
+)
+
+// selectResponse is a PPSE SELECT response listing two applications.
+const selectResponse = "6F37840E325041592E5359532E4444463031A525BF0C22" +
+	"610C4F07A0000000041010870101" +
+	"61124F07A0000000031010500456495341870102"
+
+func parseQueryFixture(t *testing.T) []TLV {
+	t.Helper()
+	parser := &Parser{}
+	tlvs, err := parser.Parse(hexToBytes(selectResponse))
+	if err != nil {
+		t.Fatalf("Parser.Parse() unexpected error = %v", err)
+	}
+	return tlvs
+}
+
+func TestFind(t *testing.T) {
+	tlvs := parseQueryFixture(t)
+
+	got, ok := Find(tlvs, 0x4F)
+	if !ok {
+		t.Fatalf("Find(4F) not found")
+	}
+	if got.ValueHex() != "A0000000041010" {
+		t.Errorf("Find(4F) = %s, want A0000000041010", got.ValueHex())
+	}
+
+	if _, ok := Find(tlvs, 0x5A); ok {
+		t.Errorf("Find(5A) found, want not found")
+	}
+
+	template, _ := Find(tlvs, 0xA5)
+	if _, ok := template.Find(0x84); ok {
+		t.Errorf("TLV.Find(84) searched outside the template")
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	tlvs := parseQueryFixture(t)
+
+	got := FindAll(tlvs, 0x4F)
+	if len(got) != 2 {
+		t.Fatalf("FindAll(4F) returned %d TLVs, want 2", len(got))
+	}
+	if got[0].ValueHex() != "A0000000041010" || got[1].ValueHex() != "A0000000031010" {
+		t.Errorf("FindAll(4F) = [%s %s], want in document order", got[0].ValueHex(), got[1].ValueHex())
+	}
+
+	if got := FindAll(tlvs, 0x5A); len(got) != 0 {
+		t.Errorf("FindAll(5A) returned %d TLVs, want 0", len(got))
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		want      string
+		wantErr   error
+		wantOther bool
+	}{
+		{name: "full path", path: "6F/A5/BF0C/61/4F", want: "A0000000041010"},
+		{name: "backtracks into second template", path: "6F/A5/BF0C/61/50", want: "56495341"},
+		{name: "lower case path", path: "6f/a5/bf0c/61/87", want: "01"},
+		{name: "missing leaf", path: "6F/A5/BF0C/61/5A", wantErr: ErrNotFound},
+		{name: "segment is not a direct child", path: "6F/BF0C", wantErr: ErrNotFound},
+		{name: "invalid segment", path: "6F//4F", wantOther: true},
+	}
+
+	tlvs := parseQueryFixture(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Get(tlvs, tt.path)
+
+			switch {
+			case tt.wantOther:
+				if err == nil || errors.Is(err, ErrNotFound) {
+					t.Errorf("Get(%s) error = %v, want invalid path error", tt.path, err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Get(%s) error = %v, want %v", tt.path, err, tt.wantErr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Get(%s) unexpected error = %v", tt.path, err)
+				}
+				if got.ValueHex() != tt.want {
+					t.Errorf("Get(%s) = %s, want %s", tt.path, got.ValueHex(), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGet_NotFoundNamesSegment(t *testing.T) {
+	tlvs := parseQueryFixture(t)
+
+	_, err := Get(tlvs, "6F/A5/BF0C/70/57")
+	if err == nil || err.Error() != "tag not found: 70 in path 6F/A5/BF0C/70/57" {
+		t.Errorf("Get() error = %v, want missing segment 70 named", err)
+	}
+}
@@ -0,0 +1,99 @@
+package tlv
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Tag is a BER-TLV tag of up to four bytes, stored big-endian so that it is
+// comparable and can be used as a map key or switch case (e.g. 0x9F34).
+type Tag uint32
+
+// Class is the tag class encoded in bits 8-7 of the first tag byte.
+type Class byte
+
+const (
+	ClassUniversal Class = iota
+	ClassApplication
+	ClassContextSpecific
+	ClassPrivate
+)
+
+const maxTagBytes = 4
+
+func (c Class) String() string {
+	switch c {
+	case ClassApplication:
+		return "application"
+	case ClassContextSpecific:
+		return "context-specific"
+	case ClassPrivate:
+		return "private"
+	default:
+		return "universal"
+	}
+}
+
+// ParseTagHex parses a tag written in hex, such as "5F24".
+func ParseTagHex(s string) (Tag, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tag %q: %w", s, err)
+	}
+	if len(b) == 0 || len(b) > maxTagBytes {
+		return 0, fmt.Errorf("invalid tag %q: must be 1 to %d bytes", s, maxTagBytes)
+	}
+	return tagFromBytes(b), nil
+}
+
+func tagFromBytes(b []byte) Tag {
+	var t Tag
+	for _, c := range b {
+		t = t<<8 | Tag(c)
+	}
+	return t
+}
+
+// Len returns the number of bytes of the encoded tag.
+func (t Tag) Len() int {
+	switch {
+	case t > 0xFFFFFF:
+		return 4
+	case t > 0xFFFF:
+		return 3
+	case t > 0xFF:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Bytes returns the encoded tag.
+func (t Tag) Bytes() []byte {
+	n := t.Len()
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(t)
+		t >>= 8
+	}
+	return b
+}
+
+func (t Tag) firstByte() byte {
+	return byte(t >> (8 * (t.Len() - 1)))
+}
+
+func (t Tag) Class() Class {
+	return Class(t.firstByte() >> 6)
+}
+
+// IsConstructed reports whether bit 6 of the first tag byte is set,
+// meaning the value is itself a sequence of TLV data objects.
+func (t Tag) IsConstructed() bool {
+	return t.firstByte()&maskConstructed != 0
+}
+
+func (t Tag) String() string {
+	return strings.ToUpper(hex.EncodeToString(t.Bytes()))
+}
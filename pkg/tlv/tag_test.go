@@ -0,0 +1,70 @@
+package tlv
+
+import "testing"
+
+func TestParseTagHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Tag
+		wantErr bool
+	}{
+		{name: "single byte", input: "5A", want: 0x5A},
+		{name: "two bytes", input: "9F34", want: 0x9F34},
+		{name: "lower case", input: "5f24", want: 0x5F24},
+		{name: "three bytes", input: "DF8101", want: 0xDF8101},
+		{name: "empty", input: "", wantErr: true},
+		{name: "invalid hex", input: "5G", wantErr: true},
+		{name: "too long", input: "DF81818101", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTagHex(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTagHex() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseTagHex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTag_Accessors(t *testing.T) {
+	tests := []struct {
+		name            string
+		tag             Tag
+		wantString      string
+		wantLen         int
+		wantClass       Class
+		wantConstructed bool
+	}{
+		{name: "universal padding", tag: 0x00, wantString: "00", wantLen: 1, wantClass: ClassUniversal},
+		{name: "application primitive", tag: 0x5A, wantString: "5A", wantLen: 1, wantClass: ClassApplication},
+		{name: "application constructed", tag: 0x70, wantString: "70", wantLen: 1, wantClass: ClassApplication, wantConstructed: true},
+		{name: "context-specific primitive", tag: 0x9F34, wantString: "9F34", wantLen: 2, wantClass: ClassContextSpecific},
+		{name: "context-specific constructed", tag: 0xBF0C, wantString: "BF0C", wantLen: 2, wantClass: ClassContextSpecific, wantConstructed: true},
+		{name: "private three bytes", tag: 0xDF8101, wantString: "DF8101", wantLen: 3, wantClass: ClassPrivate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tag.String(); got != tt.wantString {
+				t.Errorf("Tag.String() = %v, want %v", got, tt.wantString)
+			}
+			if got := tt.tag.Len(); got != tt.wantLen {
+				t.Errorf("Tag.Len() = %v, want %v", got, tt.wantLen)
+			}
+			if got := tt.tag.Class(); got != tt.wantClass {
+				t.Errorf("Tag.Class() = %v, want %v", got, tt.wantClass)
+			}
+			if got := tt.tag.IsConstructed(); got != tt.wantConstructed {
+				t.Errorf("Tag.IsConstructed() = %v, want %v", got, tt.wantConstructed)
+			}
+		})
+	}
+}
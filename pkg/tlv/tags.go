@@ -0,0 +1,238 @@
+package tlv
+
+// Format is the EMV data element format as defined in EMV 4.3 Book 3, Section 4.3.
+type Format int
+
+const (
+	FormatB   Format = iota // binary
+	FormatN                 // numeric, BCD right justified and padded with leading zeros
+	FormatCN                // compressed numeric, BCD left justified and padded with trailing 'F's
+	FormatA                 // alphabetic
+	FormatAN                // alphanumeric
+	FormatANS               // alphanumeric special
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatN:
+		return "n"
+	case FormatCN:
+		return "cn"
+	case FormatA:
+		return "a"
+	case FormatAN:
+		return "an"
+	case FormatANS:
+		return "ans"
+	default:
+		return "b"
+	}
+}
+
+// Source is the entity that provides a data element during a transaction.
+type Source int
+
+const (
+	SourceCard Source = iota
+	SourceTerminal
+	SourceIssuer
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceTerminal:
+		return "terminal"
+	case SourceIssuer:
+		return "issuer"
+	default:
+		return "card"
+	}
+}
+
+// TagInfo describes a data element from the EMV dictionary. MinLen and
+// MaxLen are value lengths in bytes.
+type TagInfo struct {
+	Tag         Tag
+	Name        string
+	Format      Format
+	MinLen      int
+	MaxLen      int
+	Source      Source
+	Constructed bool
+}
+
+// ValidLength reports whether n bytes is an acceptable value length.
+func (i TagInfo) ValidLength(n int) bool {
+	return n >= i.MinLen && n <= i.MaxLen
+}
+
+const (
+	TagTrack2              Tag = 0x57
+	TagApplicationPAN      Tag = 0x5A
+	TagExpirationDate      Tag = 0x5F24
+	TagEffectiveDate       Tag = 0x5F25
+	TagIssuerCountry       Tag = 0x5F28
+	TagTransactionCurrency Tag = 0x5F2A
+	TagServiceCode         Tag = 0x5F30
+	TagPANSequenceNumber   Tag = 0x5F34
+	TagCurrencyExponent    Tag = 0x5F36
+	TagAIP                 Tag = 0x82
+	TagCVMList             Tag = 0x8E
+	TagTVR                 Tag = 0x95
+	TagTransactionDate     Tag = 0x9A
+	TagTSI                 Tag = 0x9B
+	TagAmountAuthorised    Tag = 0x9F02
+	TagAmountOther         Tag = 0x9F03
+	TagTerminalCountry     Tag = 0x9F1A
+	TagCVMResults          Tag = 0x9F34
+	TagApplicationCurrency Tag = 0x9F42
+)
+
+var dictionary = map[Tag]TagInfo{}
+
+func init() {
+	for _, info := range tagInfos {
+		dictionary[info.Tag] = info
+	}
+}
+
+// LookupTag returns the dictionary entry for a tag.
+func LookupTag(tag Tag) (TagInfo, bool) {
+	info, ok := dictionary[tag]
+	return info, ok
+}
+
+// Info returns the dictionary entry for the TLV tag.
+func (t TLV) Info() (TagInfo, bool) {
+	return LookupTag(t.Tag)
+}
+
+// tagInfos lists the EMV 4.3 Book 3 Annex A data elements plus common
+// contactless scheme tags.
+var tagInfos = []TagInfo{
+	{Tag: 0x42, Name: "Issuer Identification Number", Format: FormatN, MinLen: 3, MaxLen: 3, Source: SourceCard},
+	{Tag: 0x4F, Name: "Application Identifier (ADF Name)", Format: FormatB, MinLen: 5, MaxLen: 16, Source: SourceCard},
+	{Tag: 0x50, Name: "Application Label", Format: FormatANS, MinLen: 1, MaxLen: 16, Source: SourceCard},
+	{Tag: 0x57, Name: "Track 2 Equivalent Data", Format: FormatB, MinLen: 0, MaxLen: 19, Source: SourceCard},
+	{Tag: 0x5A, Name: "Application PAN", Format: FormatCN, MinLen: 0, MaxLen: 10, Source: SourceCard},
+	{Tag: 0x5F20, Name: "Cardholder Name", Format: FormatANS, MinLen: 2, MaxLen: 26, Source: SourceCard},
+	{Tag: 0x5F24, Name: "Application Expiration Date", Format: FormatN, MinLen: 3, MaxLen: 3, Source: SourceCard},
+	{Tag: 0x5F25, Name: "Application Effective Date", Format: FormatN, MinLen: 3, MaxLen: 3, Source: SourceCard},
+	{Tag: 0x5F28, Name: "Issuer Country Code", Format: FormatN, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x5F2A, Name: "Transaction Currency Code", Format: FormatN, MinLen: 2, MaxLen: 2, Source: SourceTerminal},
+	{Tag: 0x5F2D, Name: "Language Preference", Format: FormatAN, MinLen: 2, MaxLen: 8, Source: SourceCard},
+	{Tag: 0x5F30, Name: "Service Code", Format: FormatN, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x5F34, Name: "Application PAN Sequence Number", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x5F36, Name: "Transaction Currency Exponent", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceTerminal},
+	{Tag: 0x5F50, Name: "Issuer URL", Format: FormatANS, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x5F53, Name: "International Bank Account Number (IBAN)", Format: FormatB, MinLen: 0, MaxLen: 34, Source: SourceCard},
+	{Tag: 0x5F54, Name: "Bank Identifier Code (BIC)", Format: FormatB, MinLen: 8, MaxLen: 11, Source: SourceCard},
+	{Tag: 0x5F55, Name: "Issuer Country Code (alpha2)", Format: FormatA, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x5F56, Name: "Issuer Country Code (alpha3)", Format: FormatA, MinLen: 3, MaxLen: 3, Source: SourceCard},
+	{Tag: 0x61, Name: "Application Template", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard, Constructed: true},
+	{Tag: 0x6F, Name: "File Control Information (FCI) Template", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard, Constructed: true},
+	{Tag: 0x70, Name: "READ RECORD Response Message Template", Format: FormatB, MinLen: 0, MaxLen: 253, Source: SourceCard, Constructed: true},
+	{Tag: 0x71, Name: "Issuer Script Template 1", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceIssuer, Constructed: true},
+	{Tag: 0x72, Name: "Issuer Script Template 2", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceIssuer, Constructed: true},
+	{Tag: 0x73, Name: "Directory Discretionary Template", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard, Constructed: true},
+	{Tag: 0x77, Name: "Response Message Template Format 2", Format: FormatB, MinLen: 0, MaxLen: 253, Source: SourceCard, Constructed: true},
+	{Tag: 0x80, Name: "Response Message Template Format 1", Format: FormatB, MinLen: 0, MaxLen: 253, Source: SourceCard},
+	{Tag: 0x81, Name: "Amount, Authorised (Binary)", Format: FormatB, MinLen: 4, MaxLen: 4, Source: SourceTerminal},
+	{Tag: 0x82, Name: "Application Interchange Profile", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x83, Name: "Command Template", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceTerminal},
+	{Tag: 0x84, Name: "Dedicated File (DF) Name", Format: FormatB, MinLen: 5, MaxLen: 16, Source: SourceCard},
+	{Tag: 0x86, Name: "Issuer Script Command", Format: FormatB, MinLen: 0, MaxLen: 261, Source: SourceIssuer},
+	{Tag: 0x87, Name: "Application Priority Indicator", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x88, Name: "Short File Identifier (SFI)", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x89, Name: "Authorisation Code", Format: FormatAN, MinLen: 6, MaxLen: 6, Source: SourceIssuer},
+	{Tag: 0x8A, Name: "Authorisation Response Code", Format: FormatAN, MinLen: 2, MaxLen: 2, Source: SourceIssuer},
+	{Tag: 0x8C, Name: "Card Risk Management Data Object List 1 (CDOL1)", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x8D, Name: "Card Risk Management Data Object List 2 (CDOL2)", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x8E, Name: "Cardholder Verification Method (CVM) List", Format: FormatB, MinLen: 10, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x8F, Name: "Certification Authority Public Key Index", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x90, Name: "Issuer Public Key Certificate", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x91, Name: "Issuer Authentication Data", Format: FormatB, MinLen: 8, MaxLen: 16, Source: SourceIssuer},
+	{Tag: 0x92, Name: "Issuer Public Key Remainder", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x93, Name: "Signed Static Application Data", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x94, Name: "Application File Locator (AFL)", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x95, Name: "Terminal Verification Results", Format: FormatB, MinLen: 5, MaxLen: 5, Source: SourceTerminal},
+	{Tag: 0x97, Name: "Transaction Certificate Data Object List (TDOL)", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x98, Name: "Transaction Certificate (TC) Hash Value", Format: FormatB, MinLen: 20, MaxLen: 20, Source: SourceTerminal},
+	{Tag: 0x99, Name: "Transaction PIN Data", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceTerminal},
+	{Tag: 0x9A, Name: "Transaction Date", Format: FormatN, MinLen: 3, MaxLen: 3, Source: SourceTerminal},
+	{Tag: 0x9B, Name: "Transaction Status Information", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceTerminal},
+	{Tag: 0x9C, Name: "Transaction Type", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceTerminal},
+	{Tag: 0x9D, Name: "Directory Definition File (DDF) Name", Format: FormatB, MinLen: 5, MaxLen: 16, Source: SourceCard},
+	{Tag: 0xA5, Name: "File Control Information (FCI) Proprietary Template", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard, Constructed: true},
+	{Tag: 0xBF0C, Name: "File Control Information (FCI) Issuer Discretionary Data", Format: FormatB, MinLen: 0, MaxLen: 222, Source: SourceCard, Constructed: true},
+	{Tag: 0x9F01, Name: "Acquirer Identifier", Format: FormatN, MinLen: 6, MaxLen: 6, Source: SourceTerminal},
+	{Tag: 0x9F02, Name: "Amount, Authorised (Numeric)", Format: FormatN, MinLen: 6, MaxLen: 6, Source: SourceTerminal},
+	{Tag: 0x9F03, Name: "Amount, Other (Numeric)", Format: FormatN, MinLen: 6, MaxLen: 6, Source: SourceTerminal},
+	{Tag: 0x9F04, Name: "Amount, Other (Binary)", Format: FormatB, MinLen: 4, MaxLen: 4, Source: SourceTerminal},
+	{Tag: 0x9F05, Name: "Application Discretionary Data", Format: FormatB, MinLen: 1, MaxLen: 32, Source: SourceCard},
+	{Tag: 0x9F06, Name: "Application Identifier (AID) - terminal", Format: FormatB, MinLen: 5, MaxLen: 16, Source: SourceTerminal},
+	{Tag: 0x9F07, Name: "Application Usage Control", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F08, Name: "Application Version Number (card)", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F09, Name: "Application Version Number (terminal)", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceTerminal},
+	{Tag: 0x9F0B, Name: "Cardholder Name Extended", Format: FormatANS, MinLen: 27, MaxLen: 45, Source: SourceCard},
+	{Tag: 0x9F0D, Name: "Issuer Action Code - Default", Format: FormatB, MinLen: 5, MaxLen: 5, Source: SourceCard},
+	{Tag: 0x9F0E, Name: "Issuer Action Code - Denial", Format: FormatB, MinLen: 5, MaxLen: 5, Source: SourceCard},
+	{Tag: 0x9F0F, Name: "Issuer Action Code - Online", Format: FormatB, MinLen: 5, MaxLen: 5, Source: SourceCard},
+	{Tag: 0x9F10, Name: "Issuer Application Data", Format: FormatB, MinLen: 0, MaxLen: 32, Source: SourceCard},
+	{Tag: 0x9F11, Name: "Issuer Code Table Index", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x9F12, Name: "Application Preferred Name", Format: FormatANS, MinLen: 1, MaxLen: 16, Source: SourceCard},
+	{Tag: 0x9F13, Name: "Last Online Application Transaction Counter (ATC) Register", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F14, Name: "Lower Consecutive Offline Limit", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x9F15, Name: "Merchant Category Code", Format: FormatN, MinLen: 2, MaxLen: 2, Source: SourceTerminal},
+	{Tag: 0x9F16, Name: "Merchant Identifier", Format: FormatANS, MinLen: 15, MaxLen: 15, Source: SourceTerminal},
+	{Tag: 0x9F17, Name: "PIN Try Counter", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x9F18, Name: "Issuer Script Identifier", Format: FormatB, MinLen: 4, MaxLen: 4, Source: SourceIssuer},
+	{Tag: 0x9F1A, Name: "Terminal Country Code", Format: FormatN, MinLen: 2, MaxLen: 2, Source: SourceTerminal},
+	{Tag: 0x9F1B, Name: "Terminal Floor Limit", Format: FormatB, MinLen: 4, MaxLen: 4, Source: SourceTerminal},
+	{Tag: 0x9F1C, Name: "Terminal Identification", Format: FormatAN, MinLen: 8, MaxLen: 8, Source: SourceTerminal},
+	{Tag: 0x9F1D, Name: "Terminal Risk Management Data", Format: FormatB, MinLen: 1, MaxLen: 8, Source: SourceTerminal},
+	{Tag: 0x9F1E, Name: "Interface Device (IFD) Serial Number", Format: FormatAN, MinLen: 8, MaxLen: 8, Source: SourceTerminal},
+	{Tag: 0x9F1F, Name: "Track 1 Discretionary Data", Format: FormatANS, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x9F20, Name: "Track 2 Discretionary Data", Format: FormatCN, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x9F21, Name: "Transaction Time", Format: FormatN, MinLen: 3, MaxLen: 3, Source: SourceTerminal},
+	{Tag: 0x9F22, Name: "Certification Authority Public Key Index (terminal)", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceTerminal},
+	{Tag: 0x9F23, Name: "Upper Consecutive Offline Limit", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x9F26, Name: "Application Cryptogram", Format: FormatB, MinLen: 8, MaxLen: 8, Source: SourceCard},
+	{Tag: 0x9F27, Name: "Cryptogram Information Data", Format: FormatB, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x9F2D, Name: "ICC PIN Encipherment Public Key Certificate", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x9F2E, Name: "ICC PIN Encipherment Public Key Exponent", Format: FormatB, MinLen: 1, MaxLen: 3, Source: SourceCard},
+	{Tag: 0x9F2F, Name: "ICC PIN Encipherment Public Key Remainder", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x9F32, Name: "Issuer Public Key Exponent", Format: FormatB, MinLen: 1, MaxLen: 3, Source: SourceCard},
+	{Tag: 0x9F33, Name: "Terminal Capabilities", Format: FormatB, MinLen: 3, MaxLen: 3, Source: SourceTerminal},
+	{Tag: 0x9F34, Name: "Cardholder Verification Method (CVM) Results", Format: FormatB, MinLen: 3, MaxLen: 3, Source: SourceTerminal},
+	{Tag: 0x9F35, Name: "Terminal Type", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceTerminal},
+	{Tag: 0x9F36, Name: "Application Transaction Counter (ATC)", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F37, Name: "Unpredictable Number", Format: FormatB, MinLen: 4, MaxLen: 4, Source: SourceTerminal},
+	{Tag: 0x9F38, Name: "Processing Options Data Object List (PDOL)", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x9F39, Name: "Point-of-Service (POS) Entry Mode", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceTerminal},
+	{Tag: 0x9F3A, Name: "Amount, Reference Currency", Format: FormatB, MinLen: 4, MaxLen: 4, Source: SourceTerminal},
+	{Tag: 0x9F3B, Name: "Application Reference Currency", Format: FormatN, MinLen: 2, MaxLen: 8, Source: SourceCard},
+	{Tag: 0x9F3C, Name: "Transaction Reference Currency Code", Format: FormatN, MinLen: 2, MaxLen: 2, Source: SourceTerminal},
+	{Tag: 0x9F3D, Name: "Transaction Reference Currency Exponent", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceTerminal},
+	{Tag: 0x9F40, Name: "Additional Terminal Capabilities", Format: FormatB, MinLen: 5, MaxLen: 5, Source: SourceTerminal},
+	{Tag: 0x9F41, Name: "Transaction Sequence Counter", Format: FormatN, MinLen: 2, MaxLen: 4, Source: SourceTerminal},
+	{Tag: 0x9F42, Name: "Application Currency Code", Format: FormatN, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F43, Name: "Application Reference Currency Exponent", Format: FormatN, MinLen: 1, MaxLen: 4, Source: SourceCard},
+	{Tag: 0x9F44, Name: "Application Currency Exponent", Format: FormatN, MinLen: 1, MaxLen: 1, Source: SourceCard},
+	{Tag: 0x9F45, Name: "Data Authentication Code", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F46, Name: "ICC Public Key Certificate", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x9F47, Name: "ICC Public Key Exponent", Format: FormatB, MinLen: 1, MaxLen: 3, Source: SourceCard},
+	{Tag: 0x9F48, Name: "ICC Public Key Remainder", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x9F49, Name: "Dynamic Data Authentication Data Object List (DDOL)", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x9F4A, Name: "Static Data Authentication Tag List", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x9F4B, Name: "Signed Dynamic Application Data", Format: FormatB, MinLen: 0, MaxLen: 248, Source: SourceCard},
+	{Tag: 0x9F4C, Name: "ICC Dynamic Number", Format: FormatB, MinLen: 2, MaxLen: 8, Source: SourceCard},
+	{Tag: 0x9F4D, Name: "Log Entry", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F4E, Name: "Merchant Name and Location", Format: FormatANS, MinLen: 0, MaxLen: 252, Source: SourceTerminal},
+	{Tag: 0x9F4F, Name: "Log Format", Format: FormatB, MinLen: 0, MaxLen: 252, Source: SourceCard},
+	{Tag: 0x9F5D, Name: "Available Offline Spending Amount", Format: FormatN, MinLen: 6, MaxLen: 6, Source: SourceCard},
+	{Tag: 0x9F66, Name: "Terminal Transaction Qualifiers (TTQ)", Format: FormatB, MinLen: 4, MaxLen: 4, Source: SourceTerminal},
+	{Tag: 0x9F6C, Name: "Card Transaction Qualifiers (CTQ)", Format: FormatB, MinLen: 2, MaxLen: 2, Source: SourceCard},
+	{Tag: 0x9F6E, Name: "Form Factor Indicator / Third Party Data", Format: FormatB, MinLen: 4, MaxLen: 32, Source: SourceCard},
+	{Tag: 0x9F7C, Name: "Customer Exclusive Data", Format: FormatB, MinLen: 0, MaxLen: 32, Source: SourceCard},
+}
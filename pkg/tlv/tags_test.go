@@ -0,0 +1,55 @@
+package tlv
+
+import "testing"
+
+func TestLookupTag(t *testing.T) {
+	tests := []struct {
+		name            string
+		tag             Tag
+		wantOK          bool
+		wantFormat      Format
+		wantSource      Source
+		wantConstructed bool
+	}{
+		{name: "PAN", tag: 0x5A, wantOK: true, wantFormat: FormatCN, wantSource: SourceCard},
+		{name: "expiry date", tag: 0x5F24, wantOK: true, wantFormat: FormatN, wantSource: SourceCard},
+		{name: "CVM results", tag: 0x9F34, wantOK: true, wantFormat: FormatB, wantSource: SourceTerminal},
+		{name: "FCI template", tag: 0x6F, wantOK: true, wantFormat: FormatB, wantSource: SourceCard, wantConstructed: true},
+		{name: "issuer authentication data", tag: 0x91, wantOK: true, wantFormat: FormatB, wantSource: SourceIssuer},
+		{name: "unknown tag", tag: 0xDF7F, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, ok := LookupTag(tt.tag)
+
+			if ok != tt.wantOK {
+				t.Fatalf("LookupTag(%v) ok = %v, want %v", tt.tag, ok, tt.wantOK)
+			}
+
+			if ok {
+				if info.Format != tt.wantFormat {
+					t.Errorf("LookupTag(%v) Format = %v, want %v", tt.tag, info.Format, tt.wantFormat)
+				}
+				if info.Source != tt.wantSource {
+					t.Errorf("LookupTag(%v) Source = %v, want %v", tt.tag, info.Source, tt.wantSource)
+				}
+				if info.Constructed != tt.wantConstructed {
+					t.Errorf("LookupTag(%v) Constructed = %v, want %v", tt.tag, info.Constructed, tt.wantConstructed)
+				}
+			}
+		})
+	}
+}
+
+func TestDictionary_ConstructedMatchesTagBit(t *testing.T) {
+	for _, info := range tagInfos {
+		got := info.Tag.IsConstructed()
+		if got != info.Constructed {
+			t.Errorf("tag %v Constructed = %v, but tag bit 6 says %v", info.Tag, info.Constructed, got)
+		}
+		if info.MinLen > info.MaxLen {
+			t.Errorf("tag %v MinLen %d > MaxLen %d", info.Tag, info.MinLen, info.MaxLen)
+		}
+	}
+}